@@ -0,0 +1,104 @@
+// Command line-api-fake serves a fake of the LINE Platform API, so that Terraform configurations
+// using this provider can be applied without a real LINE channel.
+//
+//	go run ./cmd/line-api-fake --port 8080 --state line-api-fake.json
+//
+// Point the provider at it with endpoint = "http://localhost:8080/". The admin API under /_admin/
+// seeds LIFF apps and injects faults without authentication, so the fake listens only on
+// 127.0.0.1 unless --host is given. See internal/linefake for the details.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"terraform-provider-liff/internal/linefake"
+)
+
+const adminPrefix = "/_admin"
+
+func main() {
+	var (
+		host          string
+		port          int
+		stateFile     string
+		channelId     string
+		channelSecret string
+		maxApps       int
+		tokenTTL      time.Duration
+	)
+
+	flag.StringVar(&host, "host", "127.0.0.1", "host to listen on. Set 0.0.0.0 to accept connections from other hosts")
+	flag.IntVar(&port, "port", 8080, "port to listen on")
+	flag.StringVar(&stateFile, "state", "", "JSON file to load and save LIFF apps and tokens. The state is kept in memory when empty")
+	flag.StringVar(&channelId, "channel-id", linefake.DefaultChannelId, "channel ID accepted by the token endpoint")
+	flag.StringVar(&channelSecret, "channel-secret", linefake.DefaultChannelSecret, "channel secret accepted by the token endpoint")
+	flag.IntVar(&maxApps, "max-apps", linefake.DefaultMaxApps, "number of LIFF apps allowed in the channel")
+	flag.DurationVar(&tokenTTL, "token-ttl", linefake.DefaultTokenTTL, "lifetime of issued channel access tokens")
+	flag.Parse()
+
+	var fake *linefake.Server
+	opts := linefake.Options{
+		ChannelId:     channelId,
+		ChannelSecret: channelSecret,
+		MaxApps:       maxApps,
+		TokenTTL:      tokenTTL,
+	}
+	if stateFile != "" {
+		opts.OnChange = func() {
+			if err := fake.SaveState(stateFile); err != nil {
+				log.Printf("failed to save state to %s: %s", stateFile, err)
+			}
+		}
+	}
+
+	fake = linefake.New(opts)
+	if stateFile != "" {
+		if err := fake.LoadState(stateFile); err != nil {
+			log.Fatalf("failed to load state from %s: %s", stateFile, err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(adminPrefix+"/", fake.AdminHandler(adminPrefix))
+	mux.Handle("/", logRequests(fake))
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("fake LINE API for channel %s is listening on http://%s/", channelId, addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		log.Printf("%s %s (%s)", r.Method, r.URL.Path, time.Since(start).Round(time.Millisecond))
+	})
+}
@@ -1,3 +1,7 @@
 data "liff_app" "example" {
   liff_id = "0123456789-ABCD1234"
 }
+
+data "liff_app" "by_url" {
+  view_url = "https://example.com"
+}
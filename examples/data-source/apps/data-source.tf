@@ -0,0 +1,5 @@
+data "liff_apps" "example" {
+  view_type         = "full"
+  description_regex = "^shop-"
+  scope             = ["profile"]
+}
@@ -0,0 +1,8 @@
+variable "liff_id" {
+  type = string
+
+  validation {
+    condition     = provider::liff::is_valid_liff_id(var.liff_id)
+    error_message = "liff_id must be a LIFF ID such as 1234567890-AbCdEfGh."
+  }
+}
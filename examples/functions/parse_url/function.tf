@@ -0,0 +1,3 @@
+output "liff_id" {
+  value = provider::liff::parse_url("https://liff.line.me/1234567890-AbCdEfGh/items/1?tab=detail").liff_id
+}
@@ -0,0 +1,3 @@
+output "item_link" {
+  value = provider::liff::url(liff_app.example.liff_id, "/items/1", "tab=detail")
+}
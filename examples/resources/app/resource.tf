@@ -9,4 +9,9 @@ resource "liff_app" "example" {
   feature = {
     qr_code = true
   }
+
+  timeouts {
+    create = "2m"
+    delete = "2m"
+  }
 }
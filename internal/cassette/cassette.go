@@ -0,0 +1,334 @@
+// Package cassette records LINE API interactions to a JSON file and replays them, so that
+// the behaviour of the real API can be captured once and tested without credentials.
+//
+// Channel access tokens, channel secrets and JWT assertions are redacted before they are
+// written, so cassettes can be committed.
+//
+//	recorder, err := cassette.New("testdata/cassettes/example.json", cassette.ModeReplay)
+//	client := &http.Client{Transport: recorder.Wrap(nil)}
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Mode is whether a Recorder records or replays.
+type Mode string
+
+const (
+	// ModeReplay responds with the recorded interactions and never sends requests.
+	ModeReplay Mode = "replay"
+	// ModeRecord sends requests and records the interactions.
+	ModeRecord Mode = "record"
+)
+
+// Redacted replaces secrets in cassettes.
+const Redacted = "REDACTED"
+
+// sensitiveFields are redacted in form and JSON bodies.
+var sensitiveFields = map[string]bool{
+	"access_token":     true,
+	"client_secret":    true,
+	"client_assertion": true,
+	"key_id":           true,
+}
+
+// recordedRequestHeaders and recordedResponseHeaders are the headers kept in cassettes.
+var (
+	recordedRequestHeaders  = []string{"Content-Type", "Authorization"}
+	recordedResponseHeaders = []string{"Content-Type", "Retry-After", "X-Line-Request-Id"}
+)
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction is a recorded pair of request and response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder records or replays the interactions of a cassette file.
+type Recorder struct {
+	path string
+	mode Mode
+
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// New returns a Recorder for the cassette file. In ModeReplay, the file is loaded and must exist.
+// In ModeRecord, Save writes the recorded interactions to the file.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, interactions: []Interaction{}}
+
+	switch mode {
+	case ModeRecord:
+	case ModeReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cassette: %w", err)
+		}
+		var cassette Cassette
+		if err := json.Unmarshal(data, &cassette); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		r.interactions = cassette.Interactions
+		r.replayed = make([]bool, len(cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("unknown cassette mode: %q", mode)
+	}
+
+	return r, nil
+}
+
+// Mode returns whether the Recorder records or replays.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Wrap returns an http.RoundTripper which records requests sent with next, or http.DefaultTransport
+// when next is nil, in ModeRecord. In ModeReplay, next is never used.
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{recorder: r, next: next}
+}
+
+type transport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := Request{
+		Method: req.Method,
+		Path:   req.URL.RequestURI(),
+		Header: redactHeader(req.Header, recordedRequestHeaders),
+		Body:   redactBody(body, req.Header.Get("Content-Type")),
+	}
+
+	if t.recorder.mode == ModeReplay {
+		return t.recorder.replay(req, recorded)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	t.recorder.record(Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header, recordedResponseHeaders),
+			Body:       redactBody(respBody, resp.Header.Get("Content-Type")),
+		},
+	})
+
+	return resp, nil
+}
+
+func (r *Recorder) record(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, interaction)
+}
+
+// replay responds with the first interaction not replayed yet whose request matches.
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.replayed[i] || !matches(interaction.Request, recorded) {
+			continue
+		}
+		r.replayed[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no interaction left in cassette %s for %s %s", r.path, recorded.Method, recorded.Path)
+}
+
+// Save writes the recorded interactions to the cassette file. It does nothing in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	r.mu.Lock()
+	err := encoder.Encode(Cassette{Interactions: r.interactions})
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data.Bytes(), 0o644)
+}
+
+// matches reports whether the requests are the same. Form bodies are not compared, because
+// they are only sent to the token endpoint and carry credentials which differ on replay.
+func matches(recorded Request, actual Request) bool {
+	if recorded.Method != actual.Method || recorded.Path != actual.Path {
+		return false
+	}
+	if isForm(recorded.Header.Get("Content-Type")) {
+		return true
+	}
+	return equalBody(recorded.Body, actual.Body)
+}
+
+// equalBody compares JSON bodies ignoring the order of object keys.
+func equalBody(a string, b string) bool {
+	var av, bv any
+	if json.Unmarshal([]byte(a), &av) != nil || json.Unmarshal([]byte(b), &bv) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func redactHeader(header http.Header, keys []string) http.Header {
+	redacted := http.Header{}
+	for _, key := range keys {
+		if value := header.Get(key); value != "" {
+			if key == "Authorization" {
+				value = "Bearer " + Redacted
+			}
+			redacted.Set(key, value)
+		}
+	}
+	if len(redacted) == 0 {
+		return nil
+	}
+	return redacted
+}
+
+// redactBody replaces sensitiveFields in form and JSON bodies. Other bodies are kept as is.
+func redactBody(body []byte, contentType string) string {
+	if isForm(contentType) {
+		return redactForm(string(body))
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+	if !redactJSON(value) {
+		return string(body)
+	}
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+func redactForm(body string) string {
+	pairs := strings.Split(body, "&")
+	for i, pair := range pairs {
+		if key, _, ok := strings.Cut(pair, "="); ok && sensitiveFields[key] {
+			pairs[i] = key + "=" + Redacted
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// redactJSON replaces sensitiveFields in the decoded JSON value, and reports whether any was found.
+func redactJSON(value any) bool {
+	found := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if sensitiveFields[key] {
+				v[key] = Redacted
+				found = true
+			} else if redactJSON(item) {
+				found = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if redactJSON(item) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+func isForm(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/x-www-form-urlencoded")
+}
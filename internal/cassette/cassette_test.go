@@ -0,0 +1,139 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func send(t *testing.T, client *http.Client, method string, target string, contentType string, body string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, target, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret-token")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(respBody)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	listed := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret-cookie")
+		switch r.URL.Path {
+		case "/oauth2/v3/token":
+			_, _ = io.WriteString(w, `{"token_type":"Bearer","access_token":"secret-token","expires_in":900,"key_id":"secret-key-id"}`)
+		case "/liff/v1/apps":
+			listed++
+			if listed == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, `{"message":"not found"}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"apps":[{"liffId":"1234567890-abcdefgh","scope":["openid","profile"]}]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, err := New(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: recorder.Wrap(nil)}
+
+	form := url.Values{"grant_type": {"client_credentials"}, "client_id": {"1234567890"}, "client_secret": {"secret-channel-secret"}}
+	if _, body := send(t, client, http.MethodPost, server.URL+"/oauth2/v3/token", "application/x-www-form-urlencoded", form.Encode()); !strings.Contains(body, "secret-token") {
+		t.Errorf("expected the real response while recording, got %s", body)
+	}
+	send(t, client, http.MethodGet, server.URL+"/liff/v1/apps", "", "")
+	send(t, client, http.MethodGet, server.URL+"/liff/v1/apps", "", "")
+	send(t, client, http.MethodPost, server.URL+"/liff/v1/apps", "application/json", `{"view":{"type":"full"},"description":"app"}`)
+
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-token", "secret-channel-secret", "secret-key-id", "secret-cookie"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %s to be redacted from the cassette", secret)
+		}
+	}
+
+	replayer, err := New(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = &http.Client{Transport: replayer.Wrap(nil)}
+	// The host is not recorded, and the server is not used on replay.
+	server.Close()
+
+	// The token request is matched regardless of the credentials.
+	form.Set("client_secret", "another-secret")
+	resp, body := send(t, client, http.MethodPost, "https://api.line.me/oauth2/v3/token", "application/x-www-form-urlencoded", form.Encode())
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"access_token":"REDACTED"`) {
+		t.Errorf("unexpected token response: %d %s", resp.StatusCode, body)
+	}
+
+	// Interactions of the same request are replayed in the recorded order.
+	if resp, _ := send(t, client, http.MethodGet, "https://api.line.me/liff/v1/apps", "", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the first list to be 404, got %d", resp.StatusCode)
+	}
+	resp, body = send(t, client, http.MethodGet, "https://api.line.me/liff/v1/apps", "", "")
+	if resp.StatusCode != http.StatusOK || body != `{"apps":[{"liffId":"1234567890-abcdefgh","scope":["openid","profile"]}]}` {
+		t.Errorf("expected the recorded body as is, got %d %s", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected Content-Type to be replayed, got %q", resp.Header.Get("Content-Type"))
+	}
+
+	// JSON bodies are matched ignoring the order of keys, but not their values.
+	req, _ := http.NewRequest(http.MethodPost, "https://api.line.me/liff/v1/apps", strings.NewReader(`{"description":"other","view":{"type":"full"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := client.Do(req); err == nil || !strings.Contains(err.Error(), "no interaction left") {
+		t.Errorf("expected a request with another body not to be replayed, got %v", err)
+	}
+	if resp, _ := send(t, client, http.MethodPost, "https://api.line.me/liff/v1/apps", "application/json", `{"description":"app","view":{"type":"full"}}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://api.line.me/liff/v1/apps", nil)
+	if _, err := client.Do(req); err == nil {
+		t.Error("expected an error when the interactions are used up")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay); err == nil {
+		t.Error("expected an error for a missing cassette on replay")
+	}
+	if _, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeRecord); err != nil {
+		t.Errorf("expected a new cassette to be recorded, got %s", err)
+	}
+	if _, err := New("cassette.json", Mode("rewind")); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
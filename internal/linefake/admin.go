@@ -0,0 +1,106 @@
+package linefake
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// faultRequest is the JSON form of Fault for the admin API. delay is a duration string such as "2s".
+type faultRequest struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	StatusCode int    `json:"status_code"`
+	RetryAfter string `json:"retry_after"`
+	Delay      string `json:"delay"`
+	Times      int    `json:"times"`
+}
+
+// AdminHandler returns the admin API of the fake, which is served under prefix such as "/_admin".
+//
+//	GET    {prefix}/apps      lists the LIFF apps
+//	POST   {prefix}/apps      adds a LIFF app in the JSON form of LINE API without validation
+//	PUT    {prefix}/apps/{id} replaces the LIFF app without validation, such as to change it outside of Terraform
+//	DELETE {prefix}/apps      deletes every LIFF app
+//	POST   {prefix}/faults    injects a fault such as {"path": "/liff/", "status_code": 500, "times": 1}
+//	DELETE {prefix}/faults    removes every fault
+//	GET    {prefix}/requests  lists the received requests, up to MaxRequests of the latest ones
+//	DELETE {prefix}/requests  forgets the received requests
+func (s *Server) AdminHandler(prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+
+		switch {
+		case path == "/apps" && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string][]App{"apps": s.Apps()})
+
+		case path == "/apps" && r.Method == http.MethodPost:
+			var app App
+			if err := json.NewDecoder(r.Body).Decode(&app); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error(), nil)
+				return
+			}
+			app = s.AddApp(app)
+			writeJSON(w, http.StatusOK, app)
+
+		case strings.HasPrefix(path, "/apps/") && r.Method == http.MethodPut:
+			var app App
+			if err := json.NewDecoder(r.Body).Decode(&app); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error(), nil)
+				return
+			}
+			app.LiffId = strings.TrimPrefix(path, "/apps/")
+			if !s.UpdateApp(app) {
+				writeError(w, http.StatusNotFound, "The LIFF app does not exist", nil)
+				return
+			}
+			writeJSON(w, http.StatusOK, app)
+
+		case path == "/apps" && r.Method == http.MethodDelete:
+			s.mu.Lock()
+			s.apps = []App{}
+			s.mu.Unlock()
+			s.notifyChange()
+			w.WriteHeader(http.StatusNoContent)
+
+		case path == "/faults" && r.Method == http.MethodPost:
+			var req faultRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error(), nil)
+				return
+			}
+			fault := Fault{
+				Method:     req.Method,
+				Path:       req.Path,
+				StatusCode: req.StatusCode,
+				RetryAfter: req.RetryAfter,
+				Times:      req.Times,
+			}
+			if req.Delay != "" {
+				delay, err := time.ParseDuration(req.Delay)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, err.Error(), nil)
+					return
+				}
+				fault.Delay = delay
+			}
+			s.InjectFault(fault)
+			w.WriteHeader(http.StatusNoContent)
+
+		case path == "/faults" && r.Method == http.MethodDelete:
+			s.ClearFaults()
+			w.WriteHeader(http.StatusNoContent)
+
+		case path == "/requests" && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string][]Request{"requests": s.Requests()})
+
+		case path == "/requests" && r.Method == http.MethodDelete:
+			s.ClearRequests()
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			writeError(w, http.StatusNotFound, "Not found", nil)
+		}
+	})
+}
@@ -0,0 +1,354 @@
+package linefake
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// App is a LIFF app in the same JSON form as LINE API.
+type App struct {
+	LiffId               string   `json:"liffId"`
+	View                 View     `json:"view"`
+	Description          string   `json:"description"`
+	Features             Features `json:"features"`
+	PermanentLinkPattern string   `json:"permanentLinkPattern"`
+	Scope                []string `json:"scope"`
+	BotPrompt            string   `json:"botPrompt"`
+}
+
+type View struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+	// ModuleMode is left out of responses when false, as LINE does.
+	ModuleMode bool `json:"moduleMode,omitempty"`
+}
+
+type Features struct {
+	BLE    bool `json:"ble"`
+	QRCode bool `json:"qrCode"`
+}
+
+func (a App) clone() App {
+	a.Scope = slices.Clone(a.Scope)
+	return a
+}
+
+var (
+	viewTypes  = []string{"compact", "tall", "full"}
+	scopes     = []string{"openid", "profile", "chat_message.write"}
+	botPrompts = []string{"normal", "aggressive", "none"}
+)
+
+// appRequest is the body of POST /liff/v1/apps and PUT /liff/v1/apps/{liffId}.
+// Properties which are not sent are nil.
+type appRequest struct {
+	View *struct {
+		Type       *string `json:"type"`
+		URL        *string `json:"url"`
+		ModuleMode *bool   `json:"moduleMode"`
+	} `json:"view"`
+	Description *string `json:"description"`
+	Features    *struct {
+		QRCode *bool `json:"qrCode"`
+	} `json:"features"`
+	PermanentLinkPattern *string   `json:"permanentLinkPattern"`
+	Scope                *[]string `json:"scope"`
+	BotPrompt            *string   `json:"botPrompt"`
+}
+
+// validate returns the validation errors of the request. All of view.type, view.url and
+// description are required on creation.
+func (req *appRequest) validate(create bool) []ErrorDetail {
+	details := []ErrorDetail{}
+	add := func(property string, format string, args ...any) {
+		details = append(details, ErrorDetail{Property: property, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if req.View == nil {
+		if create {
+			add("view", "must not be null")
+		}
+	} else {
+		switch {
+		case req.View.Type == nil && create:
+			add("view.type", "must not be null")
+		case req.View.Type != nil && !slices.Contains(viewTypes, *req.View.Type):
+			add("view.type", "must be one of %s", strings.Join(viewTypes, ", "))
+		}
+
+		switch {
+		case req.View.URL == nil && create:
+			add("view.url", "must not be null")
+		case req.View.URL != nil:
+			if u, err := url.Parse(*req.View.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+				add("view.url", "must be a valid HTTPS URL")
+			}
+		}
+	}
+
+	switch {
+	case req.Description == nil && create:
+		add("description", "must not be null")
+	case req.Description != nil && *req.Description == "":
+		add("description", "must not be empty")
+	}
+
+	if req.PermanentLinkPattern != nil && *req.PermanentLinkPattern != "concat" {
+		add("permanentLinkPattern", "must be concat")
+	}
+
+	if req.Scope != nil {
+		for i, scope := range *req.Scope {
+			if !slices.Contains(scopes, scope) {
+				add(fmt.Sprintf("scope[%d]", i), "must be one of %s", strings.Join(scopes, ", "))
+			}
+		}
+	}
+
+	if req.BotPrompt != nil && !slices.Contains(botPrompts, *req.BotPrompt) {
+		add("botPrompt", "must be one of %s", strings.Join(botPrompts, ", "))
+	}
+
+	return details
+}
+
+// apply sets the properties sent in the request to the app.
+func (req *appRequest) apply(app *App) {
+	if req.View != nil {
+		if req.View.Type != nil {
+			app.View.Type = *req.View.Type
+		}
+		if req.View.URL != nil {
+			app.View.URL = *req.View.URL
+		}
+		if req.View.ModuleMode != nil {
+			app.View.ModuleMode = *req.View.ModuleMode
+		}
+	}
+	if req.Description != nil {
+		app.Description = *req.Description
+	}
+	if req.Features != nil && req.Features.QRCode != nil {
+		app.Features.QRCode = *req.Features.QRCode
+	}
+	if req.PermanentLinkPattern != nil {
+		app.PermanentLinkPattern = *req.PermanentLinkPattern
+	}
+	if req.Scope != nil {
+		app.Scope = sortScopes(*req.Scope)
+	}
+	if req.BotPrompt != nil {
+		app.BotPrompt = *req.BotPrompt
+	}
+}
+
+// sortScopes returns the scopes in a fixed order regardless of the requested order,
+// so that clients must not depend on the order.
+func sortScopes(requested []string) []string {
+	sorted := []string{}
+	for _, scope := range scopes {
+		if slices.Contains(requested, scope) {
+			sorted = append(sorted, scope)
+		}
+	}
+	return sorted
+}
+
+func decodeAppRequest(w http.ResponseWriter, r *http.Request) (*appRequest, bool) {
+	var req appRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "The request body is not valid JSON", nil)
+		return nil, false
+	}
+	return &req, true
+}
+
+func (s *Server) listApps(w http.ResponseWriter, _ *http.Request) {
+	apps := s.Apps()
+
+	// LINE responds 404 instead of an empty list.
+	if len(apps) == 0 {
+		writeError(w, http.StatusNotFound, "There is no LIFF app in the channel", nil)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string][]App{"apps": apps})
+}
+
+func (s *Server) createApp(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeAppRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if details := req.validate(true); len(details) > 0 {
+		writeValidationError(w, details)
+		return
+	}
+
+	app := App{
+		PermanentLinkPattern: "concat",
+		Scope:                []string{},
+		BotPrompt:            "none",
+	}
+	req.apply(&app)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.apps) >= s.opts.MaxApps {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("The number of LIFF apps in the channel has reached the limit of %d", s.opts.MaxApps), nil)
+		return
+	}
+
+	app.LiffId = s.newLiffId()
+	s.apps = append(s.apps, app)
+
+	writeJSON(w, http.StatusOK, map[string]string{"liffId": app.LiffId})
+}
+
+func (s *Server) updateApp(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeAppRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if details := req.validate(false); len(details) > 0 {
+		writeValidationError(w, details)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(liffIdOf(r))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "The LIFF app does not exist", nil)
+		return
+	}
+
+	req.apply(&s.apps[i])
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteApp(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(liffIdOf(r))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "The LIFF app does not exist", nil)
+		return
+	}
+
+	s.apps = slices.Delete(s.apps, i, i+1)
+	w.WriteHeader(http.StatusOK)
+}
+
+func liffIdOf(r *http.Request) string {
+	return strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/liff/v1/apps/"), "/")
+}
+
+func (s *Server) indexOf(liffId string) int {
+	return slices.IndexFunc(s.apps, func(app App) bool { return app.LiffId == liffId })
+}
+
+const liffIdChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// newLiffId returns an unused LIFF ID such as "1234567890-AbCdEfGh".
+func (s *Server) newLiffId() string {
+	for {
+		suffix := make([]byte, 8)
+		for i := range suffix {
+			suffix[i] = liffIdChars[rand.Intn(len(liffIdChars))]
+		}
+		liffId := s.opts.ChannelId + "-" + string(suffix)
+		if s.indexOf(liffId) < 0 {
+			return liffId
+		}
+	}
+}
+
+// Apps returns the LIFF apps in the channel.
+func (s *Server) Apps() []App {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apps := make([]App, 0, len(s.apps))
+	for _, app := range s.apps {
+		apps = append(apps, app.clone())
+	}
+	return apps
+}
+
+// GetApp returns the LIFF app.
+func (s *Server) GetApp(liffId string) (App, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(liffId)
+	if i < 0 {
+		return App{}, false
+	}
+	return s.apps[i].clone(), true
+}
+
+// AddApp adds the LIFF app to the channel without validation, such as to prepare apps
+// created outside of Terraform. A LIFF ID is generated when it is empty.
+func (s *Server) AddApp(app App) App {
+	s.mu.Lock()
+	app = app.clone()
+	if app.LiffId == "" {
+		app.LiffId = s.newLiffId()
+	}
+	if app.Scope == nil {
+		app.Scope = []string{}
+	}
+	s.apps = append(s.apps, app)
+	s.mu.Unlock()
+
+	s.notifyChange()
+	return app.clone()
+}
+
+// UpdateApp replaces the LIFF app which has the same LIFF ID without validation, such as to
+// simulate changes outside of Terraform. It returns false when there is no such app.
+func (s *Server) UpdateApp(app App) bool {
+	s.mu.Lock()
+	i := s.indexOf(app.LiffId)
+	if i >= 0 {
+		app = app.clone()
+		if app.Scope == nil {
+			app.Scope = []string{}
+		}
+		s.apps[i] = app
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		return false
+	}
+	s.notifyChange()
+	return true
+}
+
+// DeleteApp deletes the LIFF app, such as to simulate deletion outside of Terraform.
+func (s *Server) DeleteApp(liffId string) bool {
+	s.mu.Lock()
+	i := s.indexOf(liffId)
+	if i >= 0 {
+		s.apps = slices.Delete(s.apps, i, i+1)
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		return false
+	}
+	s.notifyChange()
+	return true
+}
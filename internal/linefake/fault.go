@@ -0,0 +1,87 @@
+package linefake
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Fault makes matching requests fail or slow down.
+type Fault struct {
+	// Method matches the request method. Empty matches every method.
+	Method string
+	// Path matches requests whose path starts with it. Empty matches every path.
+	Path string
+	// StatusCode is the status code of the response. 0 handles the request normally after Delay.
+	StatusCode int
+	// RetryAfter is sent as the Retry-After header when set.
+	RetryAfter string
+	// Delay is how long to wait before responding.
+	Delay time.Duration
+	// Times is the number of requests the fault applies to. 0 applies to every request.
+	Times int
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	return (f.Method == "" || f.Method == r.Method) && strings.HasPrefix(r.URL.Path, f.Path)
+}
+
+// InjectFault adds the fault. When several faults match a request, the first one applies.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes every fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// applyFault applies the first fault matching the request, and reports whether the response is written.
+func (s *Server) applyFault(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	var fault *Fault
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+		fault = f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		break
+	}
+	s.mu.Unlock()
+
+	if fault == nil {
+		return false
+	}
+
+	if fault.Delay > 0 {
+		timer := time.NewTimer(fault.Delay)
+		defer timer.Stop()
+		select {
+		case <-r.Context().Done():
+			return true
+		case <-timer.C:
+		}
+	}
+
+	if fault.StatusCode == 0 {
+		return false
+	}
+
+	if fault.RetryAfter != "" {
+		w.Header().Set("Retry-After", fault.RetryAfter)
+	}
+	writeError(w, fault.StatusCode, http.StatusText(fault.StatusCode), nil)
+	return true
+}
@@ -0,0 +1,199 @@
+// Package linefake is a fake of the LINE Platform API, which runs in memory so that the
+// provider can be tested without real channels or network.
+//
+// It implements the stateless channel access token endpoint and the LIFF app API with
+// validation, the LIFF app quota and token expiry, and can inject 429, 5xx and slow responses.
+//
+//	fake := linefake.New(linefake.Options{})
+//	server := httptest.NewServer(fake)
+//	defer server.Close()
+package linefake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultChannelId     = "1234567890"
+	DefaultChannelSecret = "fake-channel-secret"
+	// DefaultMaxApps is the number of LIFF apps LINE allows in a channel.
+	DefaultMaxApps = 30
+	// DefaultTokenTTL is the lifetime of stateless channel access tokens.
+	DefaultTokenTTL = 15 * time.Minute
+	// MaxRequests is the number of received requests kept by the fake. Older ones are dropped.
+	MaxRequests = 1000
+)
+
+// Options configures the fake. Zero values are replaced with the defaults.
+type Options struct {
+	ChannelId     string
+	ChannelSecret string
+	// MaxApps is the number of LIFF apps allowed in the channel.
+	MaxApps int
+	// TokenTTL is the lifetime of issued channel access tokens.
+	TokenTTL time.Duration
+	// Now returns the current time. It can be replaced to test token expiry.
+	Now func() time.Time
+	// OnChange is called after LIFF apps or tokens are changed, such as to save the state.
+	OnChange func()
+}
+
+// Request is a request received by the fake.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// StatusCode is the status code of the response.
+	StatusCode int `json:"status_code"`
+}
+
+// Server is the fake LINE Platform API. It is an http.Handler.
+type Server struct {
+	opts Options
+
+	mu       sync.Mutex
+	apps     []App
+	tokens   map[string]time.Time
+	faults   []*Fault
+	requests []Request
+
+	// saveMu serialises SaveState, so that an older state never overwrites a newer one.
+	saveMu sync.Mutex
+}
+
+// New returns a fake with no LIFF apps.
+func New(opts Options) *Server {
+	if opts.ChannelId == "" {
+		opts.ChannelId = DefaultChannelId
+	}
+	if opts.ChannelSecret == "" {
+		opts.ChannelSecret = DefaultChannelSecret
+	}
+	if opts.MaxApps == 0 {
+		opts.MaxApps = DefaultMaxApps
+	}
+	if opts.TokenTTL == 0 {
+		opts.TokenTTL = DefaultTokenTTL
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return &Server{
+		opts:   opts,
+		apps:   []App{},
+		tokens: map[string]time.Time{},
+	}
+}
+
+// ChannelId returns the channel ID which the fake accepts.
+func (s *Server) ChannelId() string {
+	return s.opts.ChannelId
+}
+
+// ChannelSecret returns the channel secret which the fake accepts.
+func (s *Server) ChannelSecret() string {
+	return s.opts.ChannelSecret
+}
+
+// Requests returns the requests received so far, up to MaxRequests of the latest ones.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request{}, s.requests...)
+}
+
+// ClearRequests forgets the requests received so far.
+func (s *Server) ClearRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Line-Request-Id", randomHex(16))
+	recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+
+	defer func() {
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, StatusCode: recorder.statusCode})
+		if len(s.requests) > MaxRequests {
+			s.requests = append([]Request{}, s.requests[len(s.requests)-MaxRequests:]...)
+		}
+		s.mu.Unlock()
+
+		if r.Method != http.MethodGet && recorder.statusCode == http.StatusOK {
+			s.notifyChange()
+		}
+	}()
+
+	if s.applyFault(recorder, r) {
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == "/oauth2/v3/token" && r.Method == http.MethodPost:
+		s.issueToken(recorder, r)
+	case path == "/liff/v1/apps" && r.Method == http.MethodGet:
+		s.authorize(s.listApps)(recorder, r)
+	case path == "/liff/v1/apps" && r.Method == http.MethodPost:
+		s.authorize(s.createApp)(recorder, r)
+	case strings.HasPrefix(path, "/liff/v1/apps/") && r.Method == http.MethodPut:
+		s.authorize(s.updateApp)(recorder, r)
+	case strings.HasPrefix(path, "/liff/v1/apps/") && r.Method == http.MethodDelete:
+		s.authorize(s.deleteApp)(recorder, r)
+	default:
+		writeError(recorder, http.StatusNotFound, "Not found", nil)
+	}
+}
+
+// ErrorDetail is an item of details in LINE API error responses.
+type ErrorDetail struct {
+	Message  string `json:"message"`
+	Property string `json:"property"`
+}
+
+type errorResponse struct {
+	Message string        `json:"message"`
+	Details []ErrorDetail `json:"details,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string, details []ErrorDetail) {
+	writeJSON(w, statusCode, errorResponse{Message: message, Details: details})
+}
+
+// writeValidationError responds with 400 in the same shape as LINE API.
+func writeValidationError(w http.ResponseWriter, details []ErrorDetail) {
+	writeError(w, http.StatusBadRequest, "The request body has "+strconv.Itoa(len(details))+" error(s)", details)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,205 @@
+package linefake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestServer(t *testing.T, opts Options) (*Server, *httptest.Server) {
+	t.Helper()
+	fake := New(opts)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func issueToken(t *testing.T, server *httptest.Server, fake *Server) string {
+	t.Helper()
+	resp, err := http.PostForm(server.URL+"/oauth2/v3/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {fake.ChannelId()},
+		"client_secret": {fake.ChannelSecret()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		t.Fatal(err)
+	}
+	return token.AccessToken
+}
+
+func send(t *testing.T, server *httptest.Server, method string, path string, token string, body string) (*http.Response, errorResponse) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var errResp errorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&errResp)
+	return resp, errResp
+}
+
+func TestToken(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	fake, server := newTestServer(t, Options{Now: clock.Now})
+
+	resp, err := http.PostForm(server.URL+"/oauth2/v3/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {fake.ChannelId()},
+		"client_secret": {"wrong"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a wrong secret, got %d", resp.StatusCode)
+	}
+
+	token := issueToken(t, server, fake)
+
+	if resp, _ := send(t, server, http.MethodGet, "/liff/v1/apps", token, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for no apps, got %d", resp.StatusCode)
+	}
+
+	clock.Advance(DefaultTokenTTL)
+
+	if resp, _ := send(t, server, http.MethodGet, "/liff/v1/apps", token, ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an expired token, got %d", resp.StatusCode)
+	}
+
+	// Expired tokens are not saved, and are forgotten when a token is issued.
+	if tokens := fake.State().Tokens; len(tokens) != 0 {
+		t.Errorf("expected no tokens in the state, got %v", tokens)
+	}
+	fake.IssueToken()
+	fake.mu.Lock()
+	remaining := len(fake.tokens)
+	fake.mu.Unlock()
+	if remaining != 1 {
+		t.Errorf("expected only the new token to remain, got %d tokens", remaining)
+	}
+}
+
+func TestApps(t *testing.T) {
+	fake, server := newTestServer(t, Options{MaxApps: 2})
+	token := issueToken(t, server, fake)
+
+	resp, errResp := send(t, server, http.MethodPost, "/liff/v1/apps", token, `{"view":{"type":"huge","url":"http://example.com"},"scope":["email"]}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	properties := []string{}
+	for _, detail := range errResp.Details {
+		properties = append(properties, detail.Property)
+	}
+	if strings.Join(properties, ",") != "view.type,view.url,description,scope[0]" {
+		t.Errorf("unexpected details: %v", errResp.Details)
+	}
+
+	body := `{"view":{"type":"full","url":"https://example.com"},"description":"app","scope":["profile","openid"]}`
+	for i := 0; i < 2; i++ {
+		if resp, _ := send(t, server, http.MethodPost, "/liff/v1/apps", token, body); resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+	}
+	if resp, _ := send(t, server, http.MethodPost, "/liff/v1/apps", token, body); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 beyond MaxApps, got %d", resp.StatusCode)
+	}
+
+	apps := fake.Apps()
+	if len(apps) != 2 {
+		t.Fatalf("expected 2 apps, got %d", len(apps))
+	}
+	app := apps[0]
+	if strings.Join(app.Scope, ",") != "openid,profile" || app.BotPrompt != "none" || app.PermanentLinkPattern != "concat" {
+		t.Errorf("unexpected app: %+v", app)
+	}
+
+	if resp, _ := send(t, server, http.MethodPut, "/liff/v1/apps/"+app.LiffId, token, `{"view":{"type":null,"url":null,"moduleMode":true},"botPrompt":"aggressive"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	updated, _ := fake.GetApp(app.LiffId)
+	if updated.View.Type != "full" || !updated.View.ModuleMode || updated.BotPrompt != "aggressive" {
+		t.Errorf("unexpected app: %+v", updated)
+	}
+
+	if resp, _ := send(t, server, http.MethodDelete, "/liff/v1/apps/"+app.LiffId, token, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if resp, _ := send(t, server, http.MethodDelete, "/liff/v1/apps/"+app.LiffId, token, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	fake, server := newTestServer(t, Options{})
+	token := issueToken(t, server, fake)
+
+	fake.InjectFault(Fault{Method: http.MethodGet, Path: "/liff/", StatusCode: http.StatusTooManyRequests, RetryAfter: "1", Times: 2})
+
+	for i := 0; i < 2; i++ {
+		resp, _ := send(t, server, http.MethodGet, "/liff/v1/apps", token, "")
+		if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "1" {
+			t.Errorf("expected 429 with Retry-After, got %d", resp.StatusCode)
+		}
+	}
+	if resp, _ := send(t, server, http.MethodGet, "/liff/v1/apps", token, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the fault to be used up, got %d", resp.StatusCode)
+	}
+
+	fake.InjectFault(Fault{Delay: 50 * time.Millisecond})
+	start := time.Now()
+	if resp, _ := send(t, server, http.MethodGet, "/liff/v1/apps", token, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the slow request to be handled, got %d", resp.StatusCode)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("expected the request to be delayed")
+	}
+
+	fake.ClearFaults()
+
+	requests := fake.Requests()
+	if len(requests) != 5 || requests[1].StatusCode != http.StatusTooManyRequests {
+		t.Errorf("unexpected requests: %+v", requests)
+	}
+}
@@ -0,0 +1,101 @@
+package linefake
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State is the data of the fake, which can be saved to a JSON file.
+type State struct {
+	Apps []App `json:"apps"`
+	// Tokens are the issued channel access tokens and their expiry.
+	Tokens map[string]time.Time `json:"tokens"`
+}
+
+// State returns a copy of the current data. Expired tokens are left out.
+func (s *Server) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.opts.Now()
+	state := State{Apps: make([]App, 0, len(s.apps)), Tokens: make(map[string]time.Time, len(s.tokens))}
+	for _, app := range s.apps {
+		state.Apps = append(state.Apps, app.clone())
+	}
+	for token, expiresAt := range s.tokens {
+		if now.Before(expiresAt) {
+			state.Tokens[token] = expiresAt
+		}
+	}
+	return state
+}
+
+// Restore replaces the data with the state.
+func (s *Server) Restore(state State) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apps = []App{}
+	for _, app := range state.Apps {
+		s.apps = append(s.apps, app.clone())
+	}
+	s.tokens = map[string]time.Time{}
+	for token, expiresAt := range state.Tokens {
+		s.tokens[token] = expiresAt
+	}
+}
+
+// LoadState restores the state saved in the file. A missing file is not an error.
+func (s *Server) LoadState(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	s.Restore(state)
+	return nil
+}
+
+// SaveState writes the state to the file. The file is replaced atomically, and concurrent calls
+// are serialised so that the file ends up with the latest state.
+func (s *Server) SaveState(path string) error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	data, err := json.MarshalIndent(s.State(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// notifyChange calls Options.OnChange after the data is changed.
+func (s *Server) notifyChange() {
+	if s.opts.OnChange != nil {
+		s.opts.OnChange()
+	}
+}
@@ -0,0 +1,162 @@
+package linefake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	if err := New(Options{}).LoadState(path); err != nil {
+		t.Fatalf("expected a missing state file to be ignored, got %s", err)
+	}
+
+	var fake *Server
+	var saved atomic.Int32
+	fake = New(Options{OnChange: func() {
+		saved.Add(1)
+		if err := fake.SaveState(path); err != nil {
+			t.Error(err)
+		}
+	}})
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	token := issueToken(t, server, fake)
+
+	resp, _ := send(t, server, http.MethodPost, "/liff/v1/apps", token, `{"view":{"type":"full","url":"https://example.com"},"description":"app","scope":["openid"]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if resp, _ := send(t, server, http.MethodGet, "/liff/v1/apps", token, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	// The token and the created app are saved, but reads are not.
+	if saved.Load() != 2 {
+		t.Errorf("expected the state to be saved twice, got %d", saved.Load())
+	}
+
+	restored := New(Options{})
+	if err := restored.LoadState(path); err != nil {
+		t.Fatal(err)
+	}
+	if apps := restored.Apps(); len(apps) != 1 || apps[0].LiffId != fake.Apps()[0].LiffId {
+		t.Errorf("expected the LIFF app to be restored, got %+v", apps)
+	}
+
+	// Concurrent changes are saved one by one, so the file ends up with every LIFF app.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/liff/v1/apps", strings.NewReader(`{"view":{"type":"full","url":"https://example.com"},"description":"concurrent","scope":["openid"]}`))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", "application/json")
+			if resp, err := http.DefaultClient.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	concurrent := New(Options{})
+	if err := concurrent.LoadState(path); err != nil {
+		t.Fatal(err)
+	}
+	if apps := concurrent.Apps(); len(apps) != 11 {
+		t.Errorf("expected 11 LIFF apps to be saved, got %d", len(apps))
+	}
+
+	restoredServer := httptest.NewServer(restored)
+	t.Cleanup(restoredServer.Close)
+	if resp, _ := send(t, restoredServer, http.MethodGet, "/liff/v1/apps", token, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the token to be restored, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	fake := New(Options{})
+	mux := http.NewServeMux()
+	mux.Handle("/_admin/", fake.AdminHandler("/_admin"))
+	mux.Handle("/", fake)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	token := issueToken(t, server, fake)
+
+	resp, _ := send(t, server, http.MethodPost, "/_admin/apps", "", `{"view":{"type":"full","url":"https://example.com"},"description":"seeded","scope":["openid"]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if apps := fake.Apps(); len(apps) != 1 || apps[0].Description != "seeded" || apps[0].LiffId == "" {
+		t.Errorf("expected the LIFF app to be seeded, got %+v", apps)
+	}
+
+	liffId := fake.Apps()[0].LiffId
+	resp, _ = send(t, server, http.MethodPut, "/_admin/apps/"+liffId, "", `{"view":{"type":"tall","url":"https://example.com"},"description":"changed","scope":["profile"]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if app, _ := fake.GetApp(liffId); app.Description != "changed" || app.View.Type != "tall" {
+		t.Errorf("expected the LIFF app to be changed, got %+v", app)
+	}
+	if resp, _ := send(t, server, http.MethodPut, "/_admin/apps/1234567890-missing0", "", `{}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing LIFF app, got %d", resp.StatusCode)
+	}
+
+	resp, _ = send(t, server, http.MethodPost, "/_admin/faults", "", `{"method":"GET","path":"/liff/","status_code":503,"times":1}`)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+	if resp, _ := send(t, server, http.MethodGet, "/liff/v1/apps", token, ""); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the fault to be injected, got %d", resp.StatusCode)
+	}
+	if resp, _ := send(t, server, http.MethodPost, "/_admin/faults", "", `{"delay":"soon"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected an invalid delay to be rejected, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/_admin/requests", nil)
+	listResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listResp.Body.Close()
+	var requests struct {
+		Requests []Request `json:"requests"`
+	}
+	if err := json.NewDecoder(listResp.Body).Decode(&requests); err != nil {
+		t.Fatal(err)
+	}
+	// The token request and the failed list request. Admin requests are not recorded.
+	if len(requests.Requests) != 2 || requests.Requests[1].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("unexpected requests: %+v", requests.Requests)
+	}
+
+	if resp, _ := send(t, server, http.MethodDelete, "/_admin/apps", "", ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+	if apps := fake.Apps(); len(apps) != 0 {
+		t.Errorf("expected every LIFF app to be deleted, got %+v", apps)
+	}
+
+	if resp, _ := send(t, server, http.MethodDelete, "/_admin/requests", "", ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("expected the requests to be forgotten, got %+v", requests)
+	}
+
+	// Only the latest requests are kept.
+	for i := 0; i < MaxRequests+10; i++ {
+		fake.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, fmt.Sprintf("/missing/%d", i), nil))
+	}
+	if requests := fake.Requests(); len(requests) != MaxRequests || requests[len(requests)-1].Path != fmt.Sprintf("/missing/%d", MaxRequests+9) {
+		t.Errorf("expected the latest %d requests, got %d", MaxRequests, len(requests))
+	}
+}
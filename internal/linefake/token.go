@@ -0,0 +1,80 @@
+package linefake
+
+import (
+	"net/http"
+	"strings"
+)
+
+type tokenResponse struct {
+	TokenType   string `json:"token_type"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type oauthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// issueToken implements POST /oauth2/v3/token, which issues stateless channel access tokens.
+func (s *Server) issueToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, oauthErrorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		writeJSON(w, http.StatusBadRequest, oauthErrorResponse{Error: "unsupported_grant_type", ErrorDescription: "unsupported grant_type"})
+		return
+	}
+
+	if r.PostForm.Get("client_id") != s.opts.ChannelId || r.PostForm.Get("client_secret") != s.opts.ChannelSecret {
+		writeJSON(w, http.StatusBadRequest, oauthErrorResponse{Error: "invalid_client", ErrorDescription: "invalid client_id or client_secret"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		TokenType:   "Bearer",
+		AccessToken: s.IssueToken(),
+		ExpiresIn:   int(s.opts.TokenTTL.Seconds()),
+	})
+}
+
+// IssueToken issues a channel access token directly, such as for access_token of the provider.
+// Expired tokens are forgotten then, so that they do not pile up in the saved state.
+func (s *Server) IssueToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.opts.Now()
+	for token, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, token)
+		}
+	}
+
+	token := randomHex(32)
+	s.tokens[token] = now.Add(s.opts.TokenTTL)
+	return token
+}
+
+// authorize rejects requests without a valid channel access token with 401.
+func (s *Server) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		if ok {
+			s.mu.Lock()
+			expiresAt, found := s.tokens[token]
+			ok = found && s.opts.Now().Before(expiresAt)
+			s.mu.Unlock()
+		}
+
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "Authentication failed. Confirm that the access token in the authorization header is valid.", nil)
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -3,15 +3,20 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
-	_ datasource.DataSource              = &appDataSource{}
-	_ datasource.DataSourceWithConfigure = &appDataSource{}
+	_ datasource.DataSource                     = &appDataSource{}
+	_ datasource.DataSourceWithConfigure        = &appDataSource{}
+	_ datasource.DataSourceWithConfigValidators = &appDataSource{}
 )
 
 func NewAppDataSource() datasource.DataSource {
@@ -33,13 +38,17 @@ type appDataSourceFeaturesModel struct {
 }
 
 type appDataSourceModel struct {
-	LiffId               types.String                `tfsdk:"liff_id"`
-	View                 *appDataSourceViewModel     `tfsdk:"view"`
-	Description          types.String                `tfsdk:"description"`
-	PermanentLinkPattern types.String                `tfsdk:"permanent_link_pattern"`
-	Features             *appDataSourceFeaturesModel `tfsdk:"features"`
-	Scope                []types.String              `tfsdk:"scope"`
-	BotPrompt            types.String                `tfsdk:"bot_prompt"`
+	LiffId                types.String                `tfsdk:"liff_id"`
+	View                  *appDataSourceViewModel     `tfsdk:"view"`
+	Description           types.String                `tfsdk:"description"`
+	PermanentLinkPattern  types.String                `tfsdk:"permanent_link_pattern"`
+	Features              *appDataSourceFeaturesModel `tfsdk:"features"`
+	Scope                 []types.String              `tfsdk:"scope"`
+	BotPrompt             types.String                `tfsdk:"bot_prompt"`
+	ViewURL               types.String                `tfsdk:"view_url"`
+	LiffURL               types.String                `tfsdk:"liff_url"`
+	LegacyURL             types.String                `tfsdk:"legacy_url"`
+	PermanentLinkTemplate types.String                `tfsdk:"permanent_link_template"`
 }
 
 func (d *appDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
@@ -67,8 +76,14 @@ func (d *appDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, re
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"liff_id": schema.StringAttribute{
-				Description: "The LIFF app ID",
-				Required:    true,
+				Description: "The LIFF app ID. Exactly one of liff_id, description or view_url is required.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"view_url": schema.StringAttribute{
+				Description: "Look up the LIFF app by its endpoint URL. Same as view.url.",
+				Optional:    true,
+				Computed:    true,
 			},
 			"view": schema.SingleNestedAttribute{
 				Computed: true,
@@ -89,7 +104,8 @@ func (d *appDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, re
 				},
 			},
 			"description": schema.StringAttribute{
-				Description: "Name of LIFF app",
+				Description: "Name of LIFF app. Can be used to look up the LIFF app.",
+				Optional:    true,
 				Computed:    true,
 			},
 			"permanent_link_pattern": schema.StringAttribute{
@@ -116,49 +132,102 @@ func (d *appDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, re
 			"bot_prompt": schema.StringAttribute{
 				Computed: true,
 			},
+			"liff_url": schema.StringAttribute{
+				Description: "The LIFF URL which opens the LIFF app, such as https://liff.line.me/{liff_id}.",
+				Computed:    true,
+			},
+			"legacy_url": schema.StringAttribute{
+				Description: "The deprecated line://app/{liff_id} form of the LIFF URL.",
+				Computed:    true,
+			},
+			"permanent_link_template": schema.StringAttribute{
+				Description: "How a page under the endpoint URL is linked when permanent_link_pattern is concat. {path} is appended to the path and {query} is merged into the query of view.url.",
+				Computed:    true,
+			},
 		},
 	}
 }
 
+func (d *appDataSource) ConfigValidators(_ context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(
+			path.MatchRoot("liff_id"),
+			path.MatchRoot("description"),
+			path.MatchRoot("view_url"),
+		),
+	}
+}
+
 func (d *appDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 
 	var state appDataSourceModel
-	req.Config.Get(ctx, &state)
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var liffApp LiffAppsListResponseItem
+	var err error
+
+	switch {
+	case !state.LiffId.IsNull():
+		ctx = tflog.SetField(ctx, "liff_id", state.LiffId.ValueString())
+		liffApp, err = d.client.GetLiffApp(ctx, state.LiffId.ValueString())
+	case !state.Description.IsNull():
+		liffApp, err = d.findLiffApp(ctx, "description", state.Description.ValueString(), func(app LiffAppsListResponseItem) string {
+			if app.Description == nil {
+				return ""
+			}
+			return *app.Description
+		})
+	default:
+		liffApp, err = d.findLiffApp(ctx, "view_url", state.ViewURL.ValueString(), func(app LiffAppsListResponseItem) string {
+			return app.View.URL
+		})
+	}
 
-	liffApp, err := d.client.GetLiffApp(state.LiffId.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to Get LIFF apps", err.Error())
 		return
 	}
 
-	state.View = &appDataSourceViewModel{
-		Type: types.StringValue(liffApp.View.Type),
-		URL:  types.StringValue(liffApp.View.URL),
-	}
-	if liffApp.View.ModuleMode != nil {
-		state.View.ModuleMode = types.BoolValue(*liffApp.View.ModuleMode)
-	}
-	if liffApp.Description != nil {
-		state.Description = types.StringValue(*liffApp.Description)
+	state = newAppDataSourceModel(liffApp)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	state.PermanentLinkPattern = types.StringValue(liffApp.PermanentLinkPattern)
+}
 
-	state.Features = &appDataSourceFeaturesModel{
-		BLE:    types.BoolValue(liffApp.Features.BLE),
-		QRCode: types.BoolValue(liffApp.Features.QRCode),
+// findLiffApp returns the only LIFF app whose field equals the value.
+func (d *appDataSource) findLiffApp(ctx context.Context, name string, value string, field func(LiffAppsListResponseItem) string) (LiffAppsListResponseItem, error) {
+	liffApps, err := d.client.ListLiffApps(ctx)
+	if err != nil {
+		return LiffAppsListResponseItem{}, err
 	}
 
-	if liffApp.Scope != nil {
-		state.Scope = []types.String{}
-		for _, scope := range liffApp.Scope {
-			state.Scope = append(state.Scope, types.StringValue(scope))
+	var matched []LiffAppsListResponseItem
+	for _, liffApp := range liffApps {
+		if field(liffApp) == value {
+			matched = append(matched, liffApp)
 		}
 	}
-	state.BotPrompt = types.StringValue(liffApp.BotPrompt)
 
-	diags := resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	switch len(matched) {
+	case 0:
+		return LiffAppsListResponseItem{}, fmt.Errorf("no LIFF app with %s %q found", name, value)
+	case 1:
+		return matched[0], nil
+	default:
+		liffIds := make([]string, 0, len(matched))
+		for _, liffApp := range matched {
+			liffIds = append(liffIds, liffApp.LiffId)
+		}
+		return LiffAppsListResponseItem{}, fmt.Errorf(
+			"%d LIFF apps with %s %q found: %s. Specify liff_id instead",
+			len(matched), name, value, strings.Join(liffIds, ", "),
+		)
 	}
 }
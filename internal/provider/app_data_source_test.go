@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"terraform-provider-liff/internal/linefake"
+)
+
+func TestAccAppDataSource(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+	app := fake.AddApp(linefake.App{
+		View:                 linefake.View{Type: "tall", URL: "https://example.com/app", ModuleMode: true},
+		Description:          "tf-acc-existing",
+		Features:             linefake.Features{QRCode: true},
+		PermanentLinkPattern: "concat",
+		Scope:                []string{"openid", "profile"},
+		BotPrompt:            "normal",
+	})
+	fake.AddApp(linefake.App{
+		View:        linefake.View{Type: "full", URL: "https://example.com/other"},
+		Description: "tf-acc-other",
+		Scope:       []string{"openid"},
+		BotPrompt:   "none",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "liff_app" "by_id" {
+  liff_id = "` + app.LiffId + `"
+}
+
+data "liff_app" "by_description" {
+  description = "tf-acc-existing"
+}
+
+data "liff_app" "by_url" {
+  view_url = "https://example.com/app"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.liff_app.by_id", "description", "tf-acc-existing"),
+					resource.TestCheckResourceAttr("data.liff_app.by_id", "view.type", "tall"),
+					resource.TestCheckResourceAttr("data.liff_app.by_id", "view.module_mode", "true"),
+					resource.TestCheckResourceAttr("data.liff_app.by_id", "features.qr_code", "true"),
+					resource.TestCheckResourceAttr("data.liff_app.by_id", "scope.#", "2"),
+					resource.TestCheckResourceAttr("data.liff_app.by_id", "bot_prompt", "normal"),
+					resource.TestCheckResourceAttr("data.liff_app.by_id", "liff_url", "https://liff.line.me/"+app.LiffId),
+					resource.TestCheckResourceAttr("data.liff_app.by_description", "liff_id", app.LiffId),
+					resource.TestCheckResourceAttr("data.liff_app.by_url", "liff_id", app.LiffId),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAppDataSource_lookupErrors(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+	for i := 0; i < 2; i++ {
+		fake.AddApp(linefake.App{
+			View:        linefake.View{Type: "full", URL: "https://example.com"},
+			Description: "tf-acc-duplicated",
+			Scope:       []string{"openid"},
+		})
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "liff_app" "test" {
+  description = "tf-acc-missing"
+}
+`,
+				ExpectError: regexp.MustCompile(`no LIFF app with description "tf-acc-missing" found`),
+			},
+			{
+				Config: providerConfig + `
+data "liff_app" "test" {
+  description = "tf-acc-duplicated"
+}
+`,
+				ExpectError: regexp.MustCompile(`2 LIFF apps with description "tf-acc-duplicated" found`),
+			},
+			{
+				Config: providerConfig + `
+data "liff_app" "test" {
+  description = "tf-acc-duplicated"
+  view_url    = "https://example.com"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+			{
+				Config: providerConfig + `
+data "liff_app" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`Missing Attribute Configuration`),
+			},
+		},
+	})
+}
+
+func TestAccAppsDataSource(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+	fake.AddApp(linefake.App{
+		View:        linefake.View{Type: "full", URL: "https://example.com/a"},
+		Description: "tf-acc-a",
+		Scope:       []string{"openid", "profile"},
+		BotPrompt:   "normal",
+	})
+	fake.AddApp(linefake.App{
+		View:        linefake.View{Type: "compact", URL: "https://example.com/b"},
+		Description: "tf-acc-b",
+		Scope:       []string{"openid"},
+		BotPrompt:   "none",
+	})
+	fake.AddApp(linefake.App{
+		View:        linefake.View{Type: "full", URL: "https://example.org/c"},
+		Description: "other",
+		Scope:       []string{"profile"},
+		BotPrompt:   "none",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "liff_apps" "all" {}
+
+data "liff_apps" "filtered" {
+  description_regex = "^tf-acc-"
+  scope             = ["openid"]
+  view_type         = "full"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.liff_apps.all", "apps.#", "3"),
+					resource.TestCheckResourceAttr("data.liff_apps.filtered", "apps.#", "1"),
+					resource.TestCheckResourceAttr("data.liff_apps.filtered", "apps.0.description", "tf-acc-a"),
+				),
+			},
+		},
+	})
+}
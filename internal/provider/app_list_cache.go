@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const defaultAppListCacheTTL = 1 * time.Minute
+
+type appListFetchFunc func(ctx context.Context) ([]LiffAppsListResponseItem, error)
+
+// appListFetch is a list request in flight. done is closed when it completes.
+type appListFetch struct {
+	done chan struct{}
+	apps []LiffAppsListResponseItem
+	err  error
+}
+
+// appListCache caches the LIFF app list of the channel. It is safe for
+// concurrent use, and concurrent callers share a single in-flight request.
+type appListCache struct {
+	mu        sync.Mutex
+	apps      []LiffAppsListResponseItem
+	fetchedAt time.Time
+	valid     bool
+	inflight  *appListFetch
+	// generation is incremented on every change so that a list fetched
+	// before the change is never stored.
+	generation uint64
+}
+
+func (a *appListCache) get(ctx context.Context, ttl time.Duration, fetch appListFetchFunc) ([]LiffAppsListResponseItem, error) {
+	for {
+		a.mu.Lock()
+		if a.valid && time.Since(a.fetchedAt) < ttl {
+			apps := append([]LiffAppsListResponseItem(nil), a.apps...)
+			a.mu.Unlock()
+			return apps, nil
+		}
+
+		call := a.inflight
+		if call == nil {
+			call = &appListFetch{done: make(chan struct{})}
+			a.inflight = call
+			generation := a.generation
+			a.mu.Unlock()
+			return a.fetch(ctx, call, generation, ttl > 0, fetch)
+		}
+		a.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-call.done:
+		}
+
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			continue
+		}
+		return append([]LiffAppsListResponseItem(nil), call.apps...), call.err
+	}
+}
+
+func (a *appListCache) fetch(ctx context.Context, call *appListFetch, generation uint64, store bool, fetch appListFetchFunc) ([]LiffAppsListResponseItem, error) {
+	apps, err := fetch(ctx)
+
+	a.mu.Lock()
+	if err == nil && store && generation == a.generation {
+		a.apps = apps
+		a.fetchedAt = time.Now()
+		a.valid = true
+	}
+	if a.inflight == call {
+		a.inflight = nil
+	}
+	a.mu.Unlock()
+
+	call.apps = apps
+	call.err = err
+	close(call.done)
+
+	return append([]LiffAppsListResponseItem(nil), apps...), err
+}
+
+// invalidate drops the cached list. A request in flight is detached so that
+// later callers fetch the list again.
+func (a *appListCache) invalidate() {
+	a.mu.Lock()
+	a.valid = false
+	a.apps = nil
+	a.inflight = nil
+	a.generation++
+	a.mu.Unlock()
+}
+
+// remove drops the app from the cached list without fetching it again.
+func (a *appListCache) remove(liffId string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.generation++
+	a.inflight = nil
+
+	if !a.valid {
+		return
+	}
+
+	apps := make([]LiffAppsListResponseItem, 0, len(a.apps))
+	for _, app := range a.apps {
+		if app.LiffId != liffId {
+			apps = append(apps, app)
+		}
+	}
+	a.apps = apps
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// newAppResourceModel converts a LIFF app returned by LINE API to the liff_app resource model.
+// LINE may leave out optional properties, so every pointer and empty value is checked here
+// rather than in each CRUD operation.
+func newAppResourceModel(liffApp LiffAppsListResponseItem) appResourceModel {
+	var state appResourceModel
+
+	state.LiffId = types.StringValue(liffApp.LiffId)
+
+	// moduleMode is omitted when the LIFF app is not in module mode.
+	moduleMode := false
+	if liffApp.View.ModuleMode != nil {
+		moduleMode = *liffApp.View.ModuleMode
+	}
+	state.View = &appResourceViewModel{
+		Type:       types.StringValue(liffApp.View.Type),
+		URL:        types.StringValue(liffApp.View.URL),
+		ModuleMode: types.BoolValue(moduleMode),
+	}
+
+	state.Description = types.StringPointerValue(liffApp.Description)
+
+	state.Features = &appResourceFeaturesModel{
+		BLE:    types.BoolValue(liffApp.Features.BLE),
+		QRCode: types.BoolValue(liffApp.Features.QRCode),
+	}
+
+	state.PermanentLinkPattern = stringValueOrNull(liffApp.PermanentLinkPattern)
+
+	if liffApp.Scope != nil {
+		scope := []attr.Value{}
+		for _, s := range liffApp.Scope {
+			scope = append(scope, types.StringValue(s))
+		}
+		state.Scope = types.SetValueMust(types.StringType, scope)
+	} else {
+		state.Scope = types.SetNull(types.StringType)
+	}
+
+	state.BotPrompt = stringValueOrNull(liffApp.BotPrompt)
+
+	state.LiffURL = types.StringValue(liffURL(liffApp.LiffId))
+	state.LegacyURL = types.StringValue(legacyLiffURL(liffApp.LiffId))
+	state.PermanentLinkTemplate = permanentLinkTemplate(liffApp.LiffId, liffApp.PermanentLinkPattern)
+
+	return state
+}
+
+// newAppDataSourceModel converts a LIFF app returned by LINE API to the liff_app data source model.
+func newAppDataSourceModel(liffApp LiffAppsListResponseItem) appDataSourceModel {
+	app := newAppResourceModel(liffApp)
+
+	var scope []types.String
+	if liffApp.Scope != nil {
+		scope = []types.String{}
+		for _, s := range liffApp.Scope {
+			scope = append(scope, types.StringValue(s))
+		}
+	}
+
+	return appDataSourceModel{
+		LiffId: app.LiffId,
+		View: &appDataSourceViewModel{
+			Type:       app.View.Type,
+			URL:        app.View.URL,
+			ModuleMode: app.View.ModuleMode,
+		},
+		Description:          app.Description,
+		PermanentLinkPattern: app.PermanentLinkPattern,
+		Features: &appDataSourceFeaturesModel{
+			BLE:    app.Features.BLE,
+			QRCode: app.Features.QRCode,
+		},
+		Scope:                 scope,
+		BotPrompt:             app.BotPrompt,
+		ViewURL:               app.View.URL,
+		LiffURL:               app.LiffURL,
+		LegacyURL:             app.LegacyURL,
+		PermanentLinkTemplate: app.PermanentLinkTemplate,
+	}
+}
+
+// stringValueOrNull returns null for the empty string, which LINE API returns for omitted properties.
+func stringValueOrNull(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
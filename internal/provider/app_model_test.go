@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestNewAppResourceModel(t *testing.T) {
+	cases := []struct {
+		name     string
+		response string
+		check    func(t *testing.T, state appResourceModel)
+	}{
+		{
+			name:     "full response",
+			response: `{"liffId":"1234567890-AbcdEfgh","view":{"type":"full","url":"https://example.com","moduleMode":true},"description":"app","permanentLinkPattern":"concat","features":{"ble":true,"qrCode":true},"scope":["profile","openid"],"botPrompt":"aggressive"}`,
+			check: func(t *testing.T, state appResourceModel) {
+				expectString(t, "liff_id", state.LiffId, types.StringValue("1234567890-AbcdEfgh"))
+				expectString(t, "view.type", state.View.Type, types.StringValue("full"))
+				expectString(t, "view.url", state.View.URL, types.StringValue("https://example.com"))
+				if !state.View.ModuleMode.ValueBool() {
+					t.Error("expected module_mode to be true")
+				}
+				expectString(t, "description", state.Description, types.StringValue("app"))
+				expectString(t, "permanent_link_pattern", state.PermanentLinkPattern, types.StringValue("concat"))
+				expectString(t, "bot_prompt", state.BotPrompt, types.StringValue("aggressive"))
+				if !state.Features.BLE.ValueBool() || !state.Features.QRCode.ValueBool() {
+					t.Error("expected features to be true")
+				}
+				// The order of scopes is not significant.
+				if !state.Scope.Equal(types.SetValueMust(types.StringType, []attr.Value{types.StringValue("openid"), types.StringValue("profile")})) {
+					t.Errorf("unexpected scope: %s", state.Scope)
+				}
+				expectString(t, "liff_url", state.LiffURL, types.StringValue("https://liff.line.me/1234567890-AbcdEfgh"))
+				expectString(t, "legacy_url", state.LegacyURL, types.StringValue("line://app/1234567890-AbcdEfgh"))
+				expectString(t, "permanent_link_template", state.PermanentLinkTemplate, types.StringValue("https://liff.line.me/1234567890-AbcdEfgh/{path}?{query}"))
+			},
+		},
+		{
+			name:     "without moduleMode",
+			response: `{"liffId":"1234567890-AbcdEfgh","view":{"type":"tall","url":"https://example.com"},"description":"app","features":{"ble":false,"qrCode":false},"scope":["openid"],"botPrompt":"none"}`,
+			check: func(t *testing.T, state appResourceModel) {
+				if state.View.ModuleMode.IsNull() || state.View.ModuleMode.ValueBool() {
+					t.Errorf("expected module_mode to be false, got %s", state.View.ModuleMode)
+				}
+				expectString(t, "permanent_link_pattern", state.PermanentLinkPattern, types.StringNull())
+				expectString(t, "permanent_link_template", state.PermanentLinkTemplate, types.StringNull())
+			},
+		},
+		{
+			name:     "without description",
+			response: `{"liffId":"1234567890-AbcdEfgh","view":{"type":"full","url":"https://example.com"},"features":{"ble":false,"qrCode":false},"scope":["openid"],"botPrompt":"normal"}`,
+			check: func(t *testing.T, state appResourceModel) {
+				expectString(t, "description", state.Description, types.StringNull())
+			},
+		},
+		{
+			name:     "without botPrompt and scope",
+			response: `{"liffId":"1234567890-AbcdEfgh","view":{"type":"compact","url":"https://example.com"},"description":"app"}`,
+			check: func(t *testing.T, state appResourceModel) {
+				expectString(t, "bot_prompt", state.BotPrompt, types.StringNull())
+				if !state.Scope.IsNull() {
+					t.Errorf("expected scope to be null, got %s", state.Scope)
+				}
+				if state.Features.BLE.ValueBool() || state.Features.QRCode.ValueBool() {
+					t.Error("expected features to be false")
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var liffApp LiffAppsListResponseItem
+			if err := json.Unmarshal([]byte(c.response), &liffApp); err != nil {
+				t.Fatal(err)
+			}
+
+			c.check(t, newAppResourceModel(liffApp))
+
+			dataSource := newAppDataSourceModel(liffApp)
+			resource := newAppResourceModel(liffApp)
+			expectString(t, "data source description", dataSource.Description, resource.Description)
+			expectString(t, "data source bot_prompt", dataSource.BotPrompt, resource.BotPrompt)
+			expectString(t, "data source view_url", dataSource.ViewURL, resource.View.URL)
+			if !dataSource.View.ModuleMode.Equal(resource.View.ModuleMode) {
+				t.Errorf("data source module_mode: expected %s, got %s", resource.View.ModuleMode, dataSource.View.ModuleMode)
+			}
+			if len(dataSource.Scope) != len(liffApp.Scope) || (liffApp.Scope == nil) != (dataSource.Scope == nil) {
+				t.Errorf("data source scope: expected %v, got %v", liffApp.Scope, dataSource.Scope)
+			}
+		})
+	}
+}
+
+func expectString(t *testing.T, name string, got types.String, expected types.String) {
+	t.Helper()
+	if !got.Equal(expected) {
+		t.Errorf("%s: expected %s, got %s", name, expected, got)
+	}
+}
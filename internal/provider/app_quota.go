@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// defaultMaxAppsPerChannel is the number of LIFF apps LINE allows in a channel.
+const defaultMaxAppsPerChannel = 30
+
+// appQuota counts the LIFF apps planned to be created by this provider process,
+// which are not in the LIFF app list of LINE yet.
+type appQuota struct {
+	mu      sync.Mutex
+	planned int
+}
+
+// reserve records a planned creation when the existing apps and the planned creations leave
+// room for it within maxApps, or regardless of it when force is true. It returns the number of
+// planned creations including it.
+func (q *appQuota) reserve(existing int, maxApps int, force bool) (planned int, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ok = existing+q.planned+1 <= maxApps
+	if !ok && !force {
+		return q.planned + 1, false
+	}
+	q.planned++
+	return q.planned, ok
+}
+
+// done is called when a planned creation is finished, whether it succeeded or not.
+// A created app is counted in the LIFF app list from then on. Every creation planned while
+// MaxAppsPerChannel is enabled is reserved, since Create can not tell which ones were.
+func (q *appQuota) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.planned > 0 {
+		q.planned--
+	}
+}
+
+// appQuotaWarningThreshold returns the number of LIFF apps from which a warning is shown.
+func appQuotaWarningThreshold(maxApps int) int {
+	return maxApps - max(maxApps/10, 1)
+}
+
+// checkAppQuota reports an error when creating one more LIFF app exceeds MaxAppsPerChannel,
+// and a warning when the channel is close to the limit. A refused creation is not counted,
+// so that it does not count against the following ones.
+//
+// replacing is true when the LIFF app replaces one deleted by -replace. Terraform deletes the
+// prior LIFF app first unless create_before_destroy is set, which is not known to the provider,
+// so exceeding the limit is only warned about, and the creation is counted either way.
+func (c *LineApiClient) checkAppQuota(ctx context.Context, diags *diag.Diagnostics, replacing bool) {
+	if c.MaxAppsPerChannel <= 0 {
+		return
+	}
+
+	liffApps, err := c.ListLiffApps(ctx)
+	if err != nil {
+		c.quota.reserve(0, c.MaxAppsPerChannel, true)
+		diags.AddWarning(
+			"Failed to check the number of LIFF apps",
+			"The LIFF app list could not be obtained to check max_apps_per_channel: "+err.Error(),
+		)
+		return
+	}
+
+	planned, ok := c.quota.reserve(len(liffApps), c.MaxAppsPerChannel, replacing)
+	total := len(liffApps) + planned
+
+	tflog.Debug(ctx, "Checking LIFF app quota", map[string]any{
+		"existing": len(liffApps),
+		"planned":  planned,
+		"max":      c.MaxAppsPerChannel,
+	})
+
+	switch {
+	case !ok && replacing:
+		diags.AddWarning(
+			"The replaced LIFF app may exceed the LIFF app limit",
+			fmt.Sprintf("The channel has %d LIFF apps and %d are planned to be created, which exceeds max_apps_per_channel of %d unless the LIFF app being replaced is deleted first. Creating the LIFF app fails if create_before_destroy is set.", len(liffApps), planned, c.MaxAppsPerChannel),
+		)
+	case !ok:
+		diags.AddError(
+			"Too many LIFF apps",
+			fmt.Sprintf("The channel has %d LIFF apps and %d are planned to be created, which exceeds max_apps_per_channel of %d. Delete unused LIFF apps before creating new ones. A LIFF app replaced after terraform taint is counted as a new one, so replace it with -replace instead.", len(liffApps), planned, c.MaxAppsPerChannel),
+		)
+	case total > appQuotaWarningThreshold(c.MaxAppsPerChannel):
+		diags.AddWarning(
+			"The channel is close to the LIFF app limit",
+			fmt.Sprintf("The channel will have %d of %d LIFF apps allowed by max_apps_per_channel.", total, c.MaxAppsPerChannel),
+		)
+	}
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestCheckAppQuota(t *testing.T) {
+	var listed atomic.Int32
+	var mu sync.Mutex
+	apps := []string{}
+	for i := 0; i < 7; i++ {
+		apps = append(apps, fmt.Sprintf("1234567890-%08d", i))
+	}
+
+	client := newTestClient(t, liffAppsHandler(&listed, &apps, &mu))
+	client.MaxAppsPerChannel = 10
+
+	// 7 existing apps. The 8th and 9th are fine, the 10th is close to the limit and the 11th exceeds it.
+	expected := []struct {
+		errors   int
+		warnings int
+	}{
+		{0, 0},
+		{0, 0},
+		{0, 1},
+		{1, 0},
+	}
+
+	for i, e := range expected {
+		var diags diag.Diagnostics
+		client.checkAppQuota(context.Background(), &diags, false)
+		if diags.ErrorsCount() != e.errors || diags.WarningsCount() != e.warnings {
+			t.Errorf("creation %d: expected %d errors and %d warnings, got %v", i+1, e.errors, e.warnings, diags)
+		}
+	}
+
+	// The refused creation is not counted against the following ones.
+	if client.quota.planned != 3 {
+		t.Errorf("expected 3 planned creations, got %d", client.quota.planned)
+	}
+
+	// A replacement over the limit is only warned about, and counted.
+	var replaceDiags diag.Diagnostics
+	client.checkAppQuota(context.Background(), &replaceDiags, true)
+	if replaceDiags.HasError() || replaceDiags.WarningsCount() != 1 {
+		t.Errorf("expected a warning for the replacement, got %v", replaceDiags)
+	}
+	if client.quota.planned != 4 {
+		t.Errorf("expected the replacement to be counted, got %d planned creations", client.quota.planned)
+	}
+
+	// Finished creations are no longer counted as planned.
+	for i := 0; i < 4; i++ {
+		client.quota.done()
+	}
+
+	var diags diag.Diagnostics
+	client.checkAppQuota(context.Background(), &diags, false)
+	if diags.HasError() || diags.WarningsCount() != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+
+	client.MaxAppsPerChannel = 0
+	client.checkAppQuota(context.Background(), &diags, false)
+	if client.quota.planned != 1 {
+		t.Errorf("expected the disabled check not to count creations, got %d", client.quota.planned)
+	}
+}
@@ -2,14 +2,24 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
+	"strings"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -21,6 +31,7 @@ var (
 	_ resource.Resource                = &appResource{}
 	_ resource.ResourceWithConfigure   = &appResource{}
 	_ resource.ResourceWithImportState = &appResource{}
+	_ resource.ResourceWithModifyPlan  = &appResource{}
 )
 
 // appResource is the resource implementation.
@@ -40,24 +51,105 @@ type appResourceFeaturesModel struct {
 }
 
 type appResourceModel struct {
-	LiffId               types.String              `tfsdk:"liff_id"`
-	View                 *appResourceViewModel     `tfsdk:"view"`
-	Description          types.String              `tfsdk:"description"`
-	Features             *appResourceFeaturesModel `tfsdk:"features"`
-	PermanentLinkPattern types.String              `tfsdk:"permanent_link_pattern"`
-	Scope                []types.String            `tfsdk:"scope"`
-	BotPrompt            types.String              `tfsdk:"bot_prompt"`
+	LiffId                types.String              `tfsdk:"liff_id"`
+	View                  *appResourceViewModel     `tfsdk:"view"`
+	Description           types.String              `tfsdk:"description"`
+	Features              *appResourceFeaturesModel `tfsdk:"features"`
+	PermanentLinkPattern  types.String              `tfsdk:"permanent_link_pattern"`
+	Scope                 types.Set                 `tfsdk:"scope"`
+	BotPrompt             types.String              `tfsdk:"bot_prompt"`
+	LiffURL               types.String              `tfsdk:"liff_url"`
+	LegacyURL             types.String              `tfsdk:"legacy_url"`
+	PermanentLinkTemplate types.String              `tfsdk:"permanent_link_template"`
+	DeletionProtection    types.Bool                `tfsdk:"deletion_protection"`
+	OnDestroy             types.String              `tfsdk:"on_destroy"`
+	Timeouts              timeouts.Value            `tfsdk:"timeouts"`
 }
 
-type ScopeListValidator struct{}
+// keepTerraformSettings copies the attributes which exist only in Terraform, not in LINE.
+// They are lost when the model is rebuilt from a LINE API response.
+func (m *appResourceModel) keepTerraformSettings(from appResourceModel) {
+	m.DeletionProtection = from.DeletionProtection
+	m.OnDestroy = from.OnDestroy
+	m.Timeouts = from.Timeouts
+}
+
+// onlyTerraformSettingsChanged reports whether the plan changes nothing of the LIFF app in LINE
+// from the state. Unknown values are computed ones left out of the configuration, which LINE keeps.
+func (m *appResourceModel) onlyTerraformSettingsChanged(state appResourceModel) bool {
+	same := func(plan attr.Value, state attr.Value) bool {
+		return plan.IsUnknown() || plan.Equal(state)
+	}
+
+	if (m.View == nil) != (state.View == nil) || (m.Features == nil) != (state.Features == nil) {
+		return false
+	}
+	if m.View != nil && !(same(m.View.Type, state.View.Type) && same(m.View.URL, state.View.URL) && same(m.View.ModuleMode, state.View.ModuleMode)) {
+		return false
+	}
+	if m.Features != nil && !(same(m.Features.BLE, state.Features.BLE) && same(m.Features.QRCode, state.Features.QRCode)) {
+		return false
+	}
+	return same(m.Description, state.Description) &&
+		same(m.PermanentLinkPattern, state.PermanentLinkPattern) &&
+		same(m.Scope, state.Scope) &&
+		same(m.BotPrompt, state.BotPrompt)
+}
+
+// privateKeyApp is the key of the private state which keeps appPrivateState. Unlike the state,
+// Terraform passes it to ModifyPlan when the LIFF app is replaced by -replace.
+const privateKeyApp = "app"
+
+// appPrivateState is what ModifyPlan needs to know about the LIFF app being replaced.
+type appPrivateState struct {
+	LiffId             string `json:"liff_id"`
+	DeletionProtection bool   `json:"deletion_protection"`
+	OnDestroy          string `json:"on_destroy"`
+}
+
+// privateStateSetter is the private state of resource responses.
+type privateStateSetter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// setAppPrivateState keeps the LIFF ID and the deletion settings of the state in the private state.
+func setAppPrivateState(ctx context.Context, private privateStateSetter, state appResourceModel) diag.Diagnostics {
+	value, err := json.Marshal(appPrivateState{
+		LiffId:             state.LiffId.ValueString(),
+		DeletionProtection: state.DeletionProtection.ValueBool(),
+		OnDestroy:          state.OnDestroy.ValueString(),
+	})
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Failed to save private state", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, privateKeyApp, value)
+}
+
+// Values of on_destroy.
+const (
+	onDestroyDelete  = "delete"
+	onDestroyAbandon = "abandon"
+)
+
+// Default timeouts of liff_app operations, which can be changed with the timeouts block.
+const (
+	defaultAppCreateTimeout = 5 * time.Minute
+	defaultAppReadTimeout   = 2 * time.Minute
+	defaultAppUpdateTimeout = 5 * time.Minute
+	defaultAppDeleteTimeout = 5 * time.Minute
+)
+
+type ScopeSetValidator struct{}
 
-func (s ScopeListValidator) Description(ctx context.Context) string {
+func (s ScopeSetValidator) Description(ctx context.Context) string {
 	return "Validate LIFF scope. openid, profile, chat_message.write are available."
 }
-func (s ScopeListValidator) MarkdownDescription(ctx context.Context) string {
+func (s ScopeSetValidator) MarkdownDescription(ctx context.Context) string {
 	return "Validate LIFF scope. `openid`, `profile`, `chat_message.write` are available."
 }
-func (s ScopeListValidator) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+func (s ScopeSetValidator) ValidateSet(ctx context.Context, req validator.SetRequest, resp *validator.SetResponse) {
 	elements := req.ConfigValue.Elements()
 	if len(elements) == 0 {
 		resp.Diagnostics.AddAttributeError(req.Path, "Empty scope", "At least one of openid or profile is required.")
@@ -86,13 +178,53 @@ func (s ScopeListValidator) ValidateList(ctx context.Context, req validator.List
 	}
 }
 
-type ScopeListPlanSortingModifier struct{}
-
-func (s ScopeListPlanSortingModifier) Description(ctx context.Context) string {
-	return "Ensure that the scope list is sorted."
+// liffAppAttributePaths maps property names in LINE API validation errors to the attribute paths of liff_app.
+var liffAppAttributePaths = map[string]path.Path{
+	"view":                 path.Root("view"),
+	"view.type":            path.Root("view").AtName("type"),
+	"view.url":             path.Root("view").AtName("url"),
+	"view.moduleMode":      path.Root("view").AtName("module_mode"),
+	"description":          path.Root("description"),
+	"features":             path.Root("features"),
+	"features.qrCode":      path.Root("features").AtName("qr_code"),
+	"permanentLinkPattern": path.Root("permanent_link_pattern"),
+	"scope":                path.Root("scope"),
+	"botPrompt":            path.Root("bot_prompt"),
 }
-func (s ScopeListPlanSortingModifier) MarkdownDescription(ctx context.Context) string {
-	return "Ensure that the scope list is sorted."
+
+// addLiffAppAPIErrorDiagnostics reports validation errors of LINE API on the matching attributes.
+// Errors which can not be mapped to an attribute are reported as a generic error.
+func addLiffAppAPIErrorDiagnostics(diags *diag.Diagnostics, summary string, err error) {
+	var apiErr *LineAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		diags.AddError(summary, err.Error())
+		return
+	}
+
+	mapped := 0
+	for _, detail := range apiErr.Details {
+		// Drop index suffix such as "scope[0]".
+		property, _, _ := strings.Cut(detail.Property, "[")
+		attributePath, ok := liffAppAttributePaths[property]
+		if !ok {
+			continue
+		}
+
+		message := fmt.Sprintf("LINE API rejected %s with status %d", detail.Property, apiErr.StatusCode)
+		if apiErr.Message != "" {
+			message += ": " + apiErr.Message
+		}
+		message += fmt.Sprintf("\n  - %s: %s", detail.Property, detail.Message)
+		if apiErr.RequestId != "" {
+			message += fmt.Sprintf("\n(X-Line-Request-Id: %s)", apiErr.RequestId)
+		}
+		diags.AddAttributeError(attributePath, summary, message)
+		mapped++
+	}
+
+	if mapped == 0 || mapped < len(apiErr.Details) {
+		diags.AddError(summary, err.Error())
+	}
 }
 
 // NewAppResource is a helper function to simplify the provider implementation.
@@ -127,9 +259,17 @@ func (r *appResource) Configure(_ context.Context, req resource.ConfigureRequest
 }
 
 // Schema defines the schema for the resource.
-func (r *appResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *appResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 
 	resp.Schema = schema.Schema{
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 		Attributes: map[string]schema.Attribute{
 			"liff_id": schema.StringAttribute{
 				Description: "The LIFF ID.",
@@ -194,20 +334,18 @@ func (r *appResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 					stringvalidator.OneOf("concat"),
 				},
 			},
-			"scope": schema.ListAttribute{
-				Description: "The permission of the LIFF app.",
+			"scope": schema.SetAttribute{
+				Description: "The permission of the LIFF app. The order is not significant.",
 				Required:    true,
 				ElementType: types.StringType,
-				Validators: []validator.List{
-					listvalidator.UniqueValues(),
-					listvalidator.IsRequired(),
-					listvalidator.SizeAtLeast(1),
-					listvalidator.ValueStringsAre(
+				Validators: []validator.Set{
+					setvalidator.IsRequired(),
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(
 						stringvalidator.OneOf("openid", "profile", "chat_message.write"),
 					),
-					ScopeListValidator{},
+					ScopeSetValidator{},
 				},
-				PlanModifiers: []planmodifier.List{},
 			},
 			"bot_prompt": schema.StringAttribute{
 				Description: "Add friends options",
@@ -217,12 +355,115 @@ func (r *appResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 					stringvalidator.OneOf("normal", "aggressive", "none"),
 				},
 			},
+			"liff_url": schema.StringAttribute{
+				Description: "The LIFF URL which opens the LIFF app, such as https://liff.line.me/{liff_id}.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"legacy_url": schema.StringAttribute{
+				Description: "The deprecated line://app/{liff_id} form of the LIFF URL.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Description: "Whether Terraform is prevented from deleting or replacing the LIFF app. Deleting a LIFF app breaks every link and QR code to it. Set false and apply before destroying it. Defaults to `deletion_protection` of the provider, which is false unless set.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"on_destroy": schema.StringAttribute{
+				Description: "What to do with the LIFF app when it is destroyed. `delete` deletes it from the channel. `abandon` only removes it from the Terraform state and leaves it in the channel, and is allowed even when `deletion_protection` is true. Defaults to `delete`.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(onDestroyDelete),
+				Validators: []validator.String{
+					stringvalidator.OneOf(onDestroyDelete, onDestroyAbandon),
+				},
+			},
+			"permanent_link_template": schema.StringAttribute{
+				Description: "How a page under the endpoint URL is linked when permanent_link_pattern is concat. {path} is appended to the path and {query} is merged into the query of view.url.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 		},
 	}
 }
 
+// ModifyPlan fills deletion_protection with the provider default, checks the number of LIFF apps
+// in the channel on creation and refuses to destroy or replace a protected LIFF app.
+func (r *appResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Destroy is refused at plan time rather than halfway through apply.
+	if req.Plan.Raw.IsNull() {
+		var state appResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if state.DeletionProtection.ValueBool() && state.OnDestroy.ValueString() != onDestroyAbandon {
+			resp.Diagnostics.AddError(
+				"LIFF app is protected from deletion",
+				fmt.Sprintf("LIFF app %s can not be deleted while deletion_protection is true. Set deletion_protection to false and apply it before destroying the LIFF app.", state.LiffId.ValueString()),
+			)
+		}
+		return
+	}
+
+	var config, plan appResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.DeletionProtection.IsNull() && r.client != nil {
+		plan.DeletionProtection = types.BoolValue(r.client.DefaultDeletionProtection)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("deletion_protection"), plan.DeletionProtection)...)
+	}
+
+	if !req.State.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	// A replacement by -replace is planned as a creation without the prior state, but with the
+	// private state of the prior LIFF app. It is refused here for a protected LIFF app, since
+	// with create_before_destroy the new LIFF app would be created before Delete refuses.
+	// terraform taint does not pass the private state, so such a replacement is refused only
+	// in Delete.
+	replaced, diags := req.Private.GetKey(ctx, privateKeyApp)
+	resp.Diagnostics.Append(diags...)
+	if len(replaced) > 0 {
+		var prior appPrivateState
+		if err := json.Unmarshal(replaced, &prior); err != nil {
+			resp.Diagnostics.AddError("Failed to read private state", err.Error())
+			return
+		}
+		if prior.DeletionProtection && prior.OnDestroy != onDestroyAbandon {
+			resp.Diagnostics.AddError(
+				"LIFF app is protected from deletion",
+				fmt.Sprintf("LIFF app %s can not be replaced while deletion_protection is true. Set deletion_protection to false and apply it before replacing the LIFF app.", prior.LiffId),
+			)
+			return
+		}
+		// An abandoned LIFF app stays in the channel, so its replacement adds a LIFF app.
+		r.client.checkAppQuota(ctx, &resp.Diagnostics, prior.OnDestroy != onDestroyAbandon)
+		return
+	}
+	r.client.checkAppQuota(ctx, &resp.Diagnostics, false)
+}
+
 // Create creates the resource and sets the initial Terraform state.
 func (r *appResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// The app counted in ModifyPlan is counted in the LIFF app list after this.
+	if r.client.MaxAppsPerChannel > 0 {
+		defer r.client.quota.done()
+	}
+
 	var plan appResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -231,6 +472,14 @@ func (r *appResource) Create(ctx context.Context, req resource.CreateRequest, re
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultAppCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 	var appCreateRequest LiffAppCreateRequest
 	if plan.View != nil {
 		appCreateRequest.View = LiffAppCreateRequestView{
@@ -258,11 +507,13 @@ func (r *appResource) Create(ctx context.Context, req resource.CreateRequest, re
 		appCreateRequest.PermanentLinkPattern = &permanentLinkPattern
 	}
 
-	if plan.Scope != nil {
-		appCreateRequest.Scope = &[]string{}
-		for _, scope := range plan.Scope {
-			*appCreateRequest.Scope = append(*appCreateRequest.Scope, scope.ValueString())
+	if !plan.Scope.IsNull() {
+		scopes := []string{}
+		resp.Diagnostics.Append(plan.Scope.ElementsAs(ctx, &scopes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+		appCreateRequest.Scope = &scopes
 	}
 
 	if !plan.BotPrompt.IsNull() && plan.BotPrompt.ValueString() != "" {
@@ -271,39 +522,28 @@ func (r *appResource) Create(ctx context.Context, req resource.CreateRequest, re
 	}
 
 	tflog.Debug(ctx, "Creating LIFF app with LINE API Client")
-	createdLiffId, err := r.client.CreateLiffApp(appCreateRequest)
+	createdLiffId, err := r.client.CreateLiffApp(ctx, appCreateRequest)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create LIFF app", err.Error())
+		addLiffAppAPIErrorDiagnostics(&resp.Diagnostics, "Failed to create LIFF app", err)
 		return
 	}
 
+	ctx = tflog.SetField(ctx, "liff_id", createdLiffId)
+
 	// obtain again
-	liffApp, err := r.client.GetLiffApp(createdLiffId)
+	liffApp, err := r.client.GetLiffApp(ctx, createdLiffId)
 
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to list Get apps", err.Error())
 		return
 	}
 
-	plan.LiffId = types.StringValue(liffApp.LiffId)
-	plan.View = &appResourceViewModel{
-		Type:       types.StringValue(liffApp.View.Type),
-		URL:        types.StringValue(liffApp.View.URL),
-		ModuleMode: types.BoolValue(*liffApp.View.ModuleMode),
-	}
-	plan.Features = &appResourceFeaturesModel{}
-
-	plan.Features.BLE = types.BoolValue(liffApp.Features.BLE)
-	plan.Features.QRCode = types.BoolValue(liffApp.Features.QRCode)
-
-	plan.Description = types.StringValue(*liffApp.Description)
-
-	plan.PermanentLinkPattern = types.StringValue(liffApp.PermanentLinkPattern)
+	state := newAppResourceModel(liffApp)
+	state.keepTerraformSettings(plan)
 
-	plan.BotPrompt = types.StringValue(liffApp.BotPrompt)
-
-	diags = resp.State.Set(ctx, &plan)
+	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(setAppPrivateState(ctx, resp.Private, state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -318,40 +558,36 @@ func (r *appResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
-	liffApp, err := r.client.GetLiffApp(state.LiffId.ValueString())
-
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to Get LIFF apps", err.Error())
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultAppReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	state.LiffId = types.StringValue(liffApp.LiffId)
-	state.View = &appResourceViewModel{
-		Type: types.StringValue(liffApp.View.Type),
-		URL:  types.StringValue(liffApp.View.URL),
-	}
-	if liffApp.View.ModuleMode != nil {
-		state.View.ModuleMode = types.BoolValue(*liffApp.View.ModuleMode)
-	}
-	if liffApp.Description != nil {
-		state.Description = types.StringValue(*liffApp.Description)
-	}
-	state.PermanentLinkPattern = types.StringValue(liffApp.PermanentLinkPattern)
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	ctx = tflog.SetField(ctx, "liff_id", state.LiffId.ValueString())
 
-	state.Features = &appResourceFeaturesModel{
-		BLE:    types.BoolValue(liffApp.Features.BLE),
-		QRCode: types.BoolValue(liffApp.Features.QRCode),
+	liffApp, err := r.client.GetLiffApp(ctx, state.LiffId.ValueString())
+
+	if isNotFound(err) {
+		tflog.Warn(ctx, "LIFF app was deleted outside of Terraform, removing from state")
+		resp.State.RemoveResource(ctx)
+		return
 	}
 
-	if liffApp.Scope != nil {
-		state.Scope = []types.String{}
-		for _, scope := range liffApp.Scope {
-			state.Scope = append(state.Scope, types.StringValue(scope))
-		}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to Get LIFF apps", err.Error())
+		return
 	}
 
-	diags = resp.State.Set(ctx, state)
+	refreshed := newAppResourceModel(liffApp)
+	refreshed.keepTerraformSettings(state)
+
+	diags = resp.State.Set(ctx, refreshed)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(setAppPrivateState(ctx, resp.Private, refreshed)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -373,6 +609,24 @@ func (r *appResource) Update(ctx context.Context, req resource.UpdateRequest, re
 		return
 	}
 
+	if plan.onlyTerraformSettingsChanged(state) {
+		tflog.Debug(ctx, "Only Terraform settings of LIFF app changed, skipping LINE API", map[string]any{"liff_id": state.LiffId.ValueString()})
+		state.keepTerraformSettings(plan)
+		diags = resp.State.Set(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		resp.Diagnostics.Append(setAppPrivateState(ctx, resp.Private, state)...)
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultAppUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	var updateRequest LiffAppUpdateRequest
 	if plan.View != nil {
 		updateRequest.View = LiffAppUpdateRequestView{}
@@ -406,11 +660,13 @@ func (r *appResource) Update(ctx context.Context, req resource.UpdateRequest, re
 		updateRequest.PermanentLinkPattern = &permanentLinkPattern
 	}
 
-	if plan.Scope != nil {
-		updateRequest.Scope = &[]string{}
-		for _, scope := range plan.Scope {
-			*updateRequest.Scope = append(*updateRequest.Scope, scope.ValueString())
+	if !plan.Scope.IsNull() {
+		scopes := []string{}
+		resp.Diagnostics.Append(plan.Scope.ElementsAs(ctx, &scopes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+		updateRequest.Scope = &scopes
 	}
 
 	if !plan.BotPrompt.IsNull() && plan.BotPrompt.ValueString() != "" {
@@ -419,42 +675,30 @@ func (r *appResource) Update(ctx context.Context, req resource.UpdateRequest, re
 	}
 
 	updatedLiffId := state.LiffId.ValueString()
+	ctx = tflog.SetField(ctx, "liff_id", updatedLiffId)
 
 	tflog.Debug(ctx, "Updating LIFF app with LINE API Client")
-	updateError := r.client.UpdateLiffApp(updatedLiffId, updateRequest)
+	updateError := r.client.UpdateLiffApp(ctx, updatedLiffId, updateRequest)
 
 	if updateError != nil {
-		resp.Diagnostics.AddError("Failed to update LIFF app", updateError.Error())
+		addLiffAppAPIErrorDiagnostics(&resp.Diagnostics, "Failed to update LIFF app", updateError)
 		return
 	}
 
 	// obtain again
-	liffApp, err := r.client.GetLiffApp(updatedLiffId)
+	liffApp, err := r.client.GetLiffApp(ctx, updatedLiffId)
 
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to list Get apps", err.Error())
 		return
 	}
 
-	plan.LiffId = types.StringValue(liffApp.LiffId)
-	plan.View = &appResourceViewModel{
-		Type:       types.StringValue(liffApp.View.Type),
-		URL:        types.StringValue(liffApp.View.URL),
-		ModuleMode: types.BoolValue(*liffApp.View.ModuleMode),
-	}
-	plan.Features = &appResourceFeaturesModel{}
-
-	plan.Features.BLE = types.BoolValue(liffApp.Features.BLE)
-	plan.Features.QRCode = types.BoolValue(liffApp.Features.QRCode)
+	state = newAppResourceModel(liffApp)
+	state.keepTerraformSettings(plan)
 
-	plan.Description = types.StringValue(*liffApp.Description)
-
-	plan.PermanentLinkPattern = types.StringValue(liffApp.PermanentLinkPattern)
-
-	plan.BotPrompt = types.StringValue(liffApp.BotPrompt)
-
-	diags = resp.State.Set(ctx, &plan)
+	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(setAppPrivateState(ctx, resp.Private, state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -470,7 +714,41 @@ func (r *appResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 		return
 	}
 
-	err := r.client.DeleteLiffApp(state.LiffId.ValueString())
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultAppDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	ctx = tflog.SetField(ctx, "liff_id", state.LiffId.ValueString())
+
+	if state.OnDestroy.ValueString() == onDestroyAbandon {
+		tflog.Warn(ctx, "Abandoning LIFF app, it is removed from the state but not deleted from the channel")
+		resp.Diagnostics.AddWarning(
+			"LIFF app was abandoned",
+			fmt.Sprintf("LIFF app %s was removed from the Terraform state because on_destroy is abandon. It still exists in the channel.", state.LiffId.ValueString()),
+		)
+		return
+	}
+
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.AddError(
+			"LIFF app is protected from deletion",
+			fmt.Sprintf("LIFF app %s can not be deleted while deletion_protection is true. Set deletion_protection to false and apply it before destroying the LIFF app.", state.LiffId.ValueString()),
+		)
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting LIFF app with LINE API Client")
+	err := r.client.DeleteLiffApp(ctx, state.LiffId.ValueString())
+
+	if isNotFound(err) {
+		tflog.Warn(ctx, "LIFF app was already deleted")
+		return
+	}
 
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to delete LIFF app", err.Error())
@@ -480,41 +758,27 @@ func (r *appResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 
 func (r *appResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	liffId := req.ID
-	liffApp, err := r.client.GetLiffApp(liffId)
+	ctx = tflog.SetField(ctx, "liff_id", liffId)
+
+	liffApp, err := r.client.GetLiffApp(ctx, liffId)
 
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to Get LIFF apps", err.Error())
 		return
 	}
 
-	var state appResourceModel
-	state.LiffId = types.StringValue(liffApp.LiffId)
-	state.View = &appResourceViewModel{
-		Type: types.StringValue(liffApp.View.Type),
-		URL:  types.StringValue(liffApp.View.URL),
-	}
-	state.BotPrompt = types.StringValue(liffApp.BotPrompt)
-	if liffApp.View.ModuleMode != nil {
-		state.View.ModuleMode = types.BoolValue(*liffApp.View.ModuleMode)
-	}
-	if liffApp.Description != nil {
-		state.Description = types.StringValue(*liffApp.Description)
-	}
-	state.PermanentLinkPattern = types.StringValue(liffApp.PermanentLinkPattern)
+	state := newAppResourceModel(liffApp)
+	state.DeletionProtection = types.BoolValue(r.client.DefaultDeletionProtection)
+	state.OnDestroy = types.StringValue(onDestroyDelete)
 
-	state.Features = &appResourceFeaturesModel{
-		BLE:    types.BoolValue(liffApp.Features.BLE),
-		QRCode: types.BoolValue(liffApp.Features.QRCode),
-	}
-
-	if liffApp.Scope != nil {
-		state.Scope = []types.String{}
-		for _, scope := range liffApp.Scope {
-			state.Scope = append(state.Scope, types.StringValue(scope))
-		}
+	// timeouts is not configured yet. Keep the null value of the schema type.
+	diags := resp.State.GetAttribute(ctx, path.Root("timeouts"), &state.Timeouts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	diags := resp.State.Set(ctx, state)
+	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -0,0 +1,521 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+
+	"terraform-provider-liff/internal/linefake"
+)
+
+func testAccCheckFakeAppCount(fake *linefake.Server, expected int) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		if apps := fake.Apps(); len(apps) != expected {
+			return fmt.Errorf("expected %d LIFF apps in the fake, got %d", expected, len(apps))
+		}
+		return nil
+	}
+}
+
+func TestAccAppResource(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckFakeAppCount(fake, 0),
+		Steps: []resource.TestStep{
+			// Create and Read
+			{
+				Config: providerConfig + `
+resource "liff_app" "test" {
+  description = "tf-acc-app"
+  view = {
+    type = "full"
+    url  = "https://example.com"
+  }
+  features = {}
+  # The fake returns scopes as openid, profile.
+  scope      = ["profile", "openid"]
+  bot_prompt = "normal"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("liff_app.test", "liff_id", regexp.MustCompile(`^1234567890-[A-Za-z0-9]{8}$`)),
+					resource.TestCheckResourceAttr("liff_app.test", "description", "tf-acc-app"),
+					resource.TestCheckResourceAttr("liff_app.test", "view.module_mode", "false"),
+					resource.TestCheckResourceAttr("liff_app.test", "features.qr_code", "false"),
+					resource.TestCheckResourceAttr("liff_app.test", "scope.#", "2"),
+					resource.TestCheckTypeSetElemAttr("liff_app.test", "scope.*", "profile"),
+					resource.TestCheckTypeSetElemAttr("liff_app.test", "scope.*", "openid"),
+					resource.TestCheckResourceAttr("liff_app.test", "bot_prompt", "normal"),
+					resource.TestCheckResourceAttr("liff_app.test", "permanent_link_pattern", "concat"),
+					resource.TestCheckResourceAttr("liff_app.test", "deletion_protection", "false"),
+					resource.TestCheckResourceAttr("liff_app.test", "on_destroy", "delete"),
+					resource.TestMatchResourceAttr("liff_app.test", "liff_url", regexp.MustCompile(`^https://liff\.line\.me/1234567890-`)),
+					testAccCheckFakeAppCount(fake, 1),
+				),
+			},
+			// ImportState
+			{
+				ResourceName:                         "liff_app.test",
+				ImportState:                          true,
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "liff_id",
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return s.RootModule().Resources["liff_app.test"].Primary.Attributes["liff_id"], nil
+				},
+			},
+			// Reordering scopes does not change anything
+			{
+				Config: providerConfig + `
+resource "liff_app" "test" {
+  description = "tf-acc-app"
+  view = {
+    type = "full"
+    url  = "https://example.com"
+  }
+  features   = {}
+  scope      = ["openid", "profile"]
+  bot_prompt = "normal"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			// Update and Read
+			{
+				Config: providerConfig + `
+resource "liff_app" "test" {
+  description = "tf-acc-app-updated"
+  view = {
+    type        = "tall"
+    url         = "https://example.com/updated"
+    module_mode = true
+  }
+  features = {
+    qr_code = true
+  }
+  scope      = ["openid", "chat_message.write"]
+  bot_prompt = "aggressive"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("liff_app.test", plancheck.ResourceActionUpdate),
+						// Computed attributes which do not change are not shown as (known after apply).
+						plancheck.ExpectKnownValue("liff_app.test", tfjsonpath.New("permanent_link_template"), knownvalue.StringRegexp(regexp.MustCompile(`^https://liff\.line\.me/1234567890-`))),
+						plancheck.ExpectKnownValue("liff_app.test", tfjsonpath.New("liff_url"), knownvalue.StringRegexp(regexp.MustCompile(`^https://liff\.line\.me/1234567890-`))),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("liff_app.test", "description", "tf-acc-app-updated"),
+					resource.TestCheckResourceAttr("liff_app.test", "view.type", "tall"),
+					resource.TestCheckResourceAttr("liff_app.test", "view.module_mode", "true"),
+					resource.TestCheckResourceAttr("liff_app.test", "features.qr_code", "true"),
+					resource.TestCheckResourceAttr("liff_app.test", "scope.#", "2"),
+					resource.TestCheckResourceAttr("liff_app.test", "bot_prompt", "aggressive"),
+					func(_ *terraform.State) error {
+						app := fake.Apps()[0]
+						if app.Description != "tf-acc-app-updated" || app.View.URL != "https://example.com/updated" || !app.Features.QRCode {
+							return fmt.Errorf("LIFF app was not updated: %+v", app)
+						}
+						return nil
+					},
+				),
+			},
+			// Changed outside of Terraform
+			{
+				PreConfig: func() {
+					app := fake.Apps()[0]
+					app.Description = "changed-outside-of-terraform"
+					fake.UpdateApp(app)
+				},
+				RefreshState: true,
+				Check:        resource.TestCheckResourceAttr("liff_app.test", "description", "changed-outside-of-terraform"),
+				RefreshPlanChecks: resource.RefreshPlanChecks{
+					PostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectNonEmptyPlan(),
+					},
+				},
+				ExpectNonEmptyPlan: true,
+			},
+			// Deleted outside of Terraform
+			{
+				PreConfig: func() {
+					for _, app := range fake.Apps() {
+						fake.DeleteApp(app.LiffId)
+					}
+				},
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccAppResource_deletionProtection(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+
+	// Changing revision replaces the LIFF app in the same way as -replace.
+	config := func(deletionProtection bool, revision int) string {
+		return providerConfig + fmt.Sprintf(`
+resource "terraform_data" "revision" {
+  input = %d
+}
+
+resource "liff_app" "test" {
+  description = "tf-acc-protected"
+  view = {
+    type = "compact"
+    url  = "https://example.com"
+  }
+  features            = {}
+  scope               = ["openid"]
+  deletion_protection = %t
+
+  lifecycle {
+    replace_triggered_by = [terraform_data.revision]
+  }
+}
+`, revision, deletionProtection)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckFakeAppCount(fake, 0),
+		Steps: []resource.TestStep{
+			{
+				Config: config(true, 1),
+				Check:  resource.TestCheckResourceAttr("liff_app.test", "deletion_protection", "true"),
+			},
+			// Removing the resource from the configuration is refused at plan time.
+			{
+				Config:      providerConfig,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`protected from deletion`),
+			},
+			{
+				Config:      config(true, 1),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`protected from deletion`),
+			},
+			// Replacement is refused at plan time too, before a new LIFF app is created.
+			{
+				Config:      config(true, 2),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`can not be replaced while deletion_protection`),
+			},
+			// Only deletion_protection changes, so the LIFF app is not updated in LINE.
+			{
+				Config: config(false, 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckFakeAppCount(fake, 1),
+					resource.TestCheckResourceAttr("liff_app.test", "deletion_protection", "false"),
+					func(_ *terraform.State) error {
+						for _, request := range fake.Requests() {
+							if request.Method == http.MethodPut {
+								return fmt.Errorf("expected no update of the LIFF app, got %s %s", request.Method, request.Path)
+							}
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccAppResource_abandon(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// The abandoned app is left in the channel.
+		CheckDestroy: testAccCheckFakeAppCount(fake, 1),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "liff_app" "test" {
+  description = "tf-acc-abandoned"
+  view = {
+    type = "full"
+    url  = "https://example.com"
+  }
+  features   = {}
+  scope      = ["openid"]
+  on_destroy = "abandon"
+}
+`,
+				Check: resource.TestCheckResourceAttr("liff_app.test", "on_destroy", "abandon"),
+			},
+		},
+	})
+}
+
+func TestAccAppResource_quota(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+	for i := 0; i < linefake.DefaultMaxApps; i++ {
+		fake.AddApp(linefake.App{
+			View:        linefake.View{Type: "full", URL: "https://example.com"},
+			Description: fmt.Sprintf("existing-%d", i),
+			Scope:       []string{"openid"},
+		})
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "liff_app" "test" {
+  description = "tf-acc-over-quota"
+  view = {
+    type = "full"
+    url  = "https://example.com"
+  }
+  features = {}
+  scope    = ["openid"]
+}
+`,
+				ExpectError: regexp.MustCompile(`Too many LIFF apps`),
+			},
+		},
+	})
+}
+
+func TestAccAppResource_quotaReplace(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+	for i := 0; i < linefake.DefaultMaxApps-1; i++ {
+		fake.AddApp(linefake.App{
+			View:        linefake.View{Type: "full", URL: "https://example.com"},
+			Description: fmt.Sprintf("existing-%d", i),
+			Scope:       []string{"openid"},
+		})
+	}
+
+	// Changing revision replaces the LIFF app in the same way as -replace.
+	config := func(onDestroy string, revision int) string {
+		return providerConfig + fmt.Sprintf(`
+resource "terraform_data" "revision" {
+  input = %d
+}
+
+resource "liff_app" "test" {
+  description = "tf-acc-replaced"
+  view = {
+    type = "full"
+    url  = "https://example.com"
+  }
+  features   = {}
+  scope      = ["openid"]
+  on_destroy = %q
+
+  lifecycle {
+    replace_triggered_by = [terraform_data.revision]
+  }
+}
+`, revision, onDestroy)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// The last LIFF app is abandoned.
+		CheckDestroy: testAccCheckFakeAppCount(fake, linefake.DefaultMaxApps),
+		Steps: []resource.TestStep{
+			{
+				Config: config(onDestroyDelete, 1),
+				Check:  testAccCheckFakeAppCount(fake, linefake.DefaultMaxApps),
+			},
+			// The prior LIFF app is deleted first, so the replacement fits in the limit.
+			{
+				Config: config(onDestroyDelete, 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("liff_app.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: testAccCheckFakeAppCount(fake, linefake.DefaultMaxApps),
+			},
+			{
+				Config: config(onDestroyAbandon, 2),
+			},
+			// An abandoned LIFF app stays in the channel, so the replacement exceeds the limit.
+			{
+				Config:      config(onDestroyAbandon, 3),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Too many LIFF apps`),
+			},
+		},
+	})
+}
+
+func TestAccAppResource_retry(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+	fake.InjectFault(linefake.Fault{Method: http.MethodGet, Path: "/liff/v1/apps", StatusCode: http.StatusTooManyRequests, RetryAfter: "0", Times: 1})
+	fake.InjectFault(linefake.Fault{Method: http.MethodGet, Path: "/liff/v1/apps", StatusCode: http.StatusInternalServerError, Times: 1})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckFakeAppCount(fake, 0),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "liff_app" "test" {
+  description = "tf-acc-retried"
+  view = {
+    type = "full"
+    url  = "https://example.com"
+  }
+  features = {}
+  scope    = ["openid"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckFakeAppCount(fake, 1),
+					func(_ *terraform.State) error {
+						failed := 0
+						for _, request := range fake.Requests() {
+							if request.StatusCode >= http.StatusTooManyRequests {
+								failed++
+							}
+						}
+						if failed != 2 {
+							return fmt.Errorf("expected 2 failed requests to be retried, got %d", failed)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccAppResource_timeouts(t *testing.T) {
+	fake, providerConfig := newTestAccFake(t, linefake.Options{})
+	config := providerConfig + `
+resource "liff_app" "test" {
+  description = "tf-acc-timeouts"
+  view = {
+    type = "full"
+    url  = "https://example.com"
+  }
+  features = {}
+  scope    = ["openid"]
+  timeouts {
+    read = "1s"
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckFakeAppCount(fake, 0),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			// Read gives up on a slow response after timeouts.read.
+			{
+				PreConfig: func() {
+					fake.InjectFault(linefake.Fault{Method: http.MethodGet, Path: "/liff/v1/apps", Delay: 3 * time.Second, Times: 1})
+				},
+				Config:      config,
+				ExpectError: regexp.MustCompile(`context deadline exceeded`),
+			},
+		},
+	})
+}
+
+// TestAccAppResource_replayFake replays its cassette, which was recorded against cmd/line-api-fake,
+// not LINE. It tests recording and replaying rather than the behaviour of LINE.
+func TestAccAppResource_replayFake(t *testing.T) {
+	factories, providerConfig := newTestAccCassette(t)
+
+	config := func(description string, scope string) string {
+		return providerConfig + fmt.Sprintf(`
+resource "liff_app" "test" {
+  description = %q
+  view = {
+    type = "full"
+    url  = "https://example.com"
+  }
+  features   = {}
+  scope      = %s
+  bot_prompt = "normal"
+}
+`, description, scope)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: factories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("tf-acc-replayed", `["profile", "openid"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("liff_app.test", "liff_id", regexp.MustCompile(`^\d+-[A-Za-z0-9]{8}$`)),
+					resource.TestCheckResourceAttr("liff_app.test", "description", "tf-acc-replayed"),
+					resource.TestCheckTypeSetElemAttr("liff_app.test", "scope.*", "profile"),
+					resource.TestCheckResourceAttr("liff_app.test", "permanent_link_pattern", "concat"),
+				),
+			},
+			{
+				Config: config("tf-acc-replayed-updated", `["openid"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("liff_app.test", "description", "tf-acc-replayed-updated"),
+					resource.TestCheckResourceAttr("liff_app.test", "scope.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAddLiffAppAPIErrorDiagnostics(t *testing.T) {
+	validationError := newLineAPIError(newErrorResponse(http.StatusBadRequest,
+		`{"message":"The request body has 2 error(s)","details":[{"message":"must be a valid URL","property":"view.url"},{"message":"invalid","property":"unknown"}]}`))
+
+	var diags diag.Diagnostics
+	addLiffAppAPIErrorDiagnostics(&diags, "Failed to create LIFF app", validationError)
+
+	if len(diags) != 2 {
+		t.Fatalf("expected an attribute error and a generic error, got %v", diags)
+	}
+
+	attributeDiag, ok := diags[0].(diag.DiagnosticWithPath)
+	if !ok || !attributeDiag.Path().Equal(path.Root("view").AtName("url")) {
+		t.Fatalf("expected an error on view.url, got %v", diags[0])
+	}
+	if attributeDiag.Summary() != "Failed to create LIFF app" {
+		t.Errorf("unexpected summary: %q", attributeDiag.Summary())
+	}
+	for _, expected := range []string{"status 400", "The request body has 2 error(s)", "view.url: must be a valid URL", "X-Line-Request-Id: request-id"} {
+		if !strings.Contains(attributeDiag.Detail(), expected) {
+			t.Errorf("expected the detail to contain %q, got %q", expected, attributeDiag.Detail())
+		}
+	}
+
+	// The unknown property is reported with the whole error.
+	if !strings.Contains(diags[1].Detail(), "unknown: invalid") {
+		t.Errorf("expected the generic error to contain every detail, got %q", diags[1].Detail())
+	}
+
+	diags = nil
+	addLiffAppAPIErrorDiagnostics(&diags, "Failed to update LIFF app", newLineAPIError(newErrorResponse(http.StatusForbidden, `{"message":"Not allowed"}`)))
+	if len(diags) != 1 || !strings.Contains(diags[0].Detail(), "status 403: Not allowed") {
+		t.Errorf("expected a generic error with the status and message, got %v", diags)
+	}
+}
@@ -0,0 +1,247 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &appsDataSource{}
+	_ datasource.DataSourceWithConfigure = &appsDataSource{}
+)
+
+func NewAppsDataSource() datasource.DataSource {
+	return &appsDataSource{}
+}
+
+type appsDataSource struct {
+	client *LineApiClient
+}
+
+type appsDataSourceModel struct {
+	ViewType         types.String         `tfsdk:"view_type"`
+	DescriptionRegex types.String         `tfsdk:"description_regex"`
+	ViewURLRegex     types.String         `tfsdk:"view_url_regex"`
+	Scope            []types.String       `tfsdk:"scope"`
+	BotPrompt        types.String         `tfsdk:"bot_prompt"`
+	Apps             []appDataSourceModel `tfsdk:"apps"`
+}
+
+func (d *appsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*LineApiClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *LineApiClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *appsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_apps"
+}
+
+func (d *appsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the LIFF apps of the channel, optionally filtered.",
+		Attributes: map[string]schema.Attribute{
+			"view_type": schema.StringAttribute{
+				Description: "Only apps with this screen size. full, tall or compact are available",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("full", "tall", "compact"),
+				},
+			},
+			"description_regex": schema.StringAttribute{
+				Description: "Only apps whose name matches this regular expression.",
+				Optional:    true,
+			},
+			"view_url_regex": schema.StringAttribute{
+				Description: "Only apps whose endpoint URL matches this regular expression.",
+				Optional:    true,
+			},
+			"scope": schema.ListAttribute{
+				Description: "Only apps which have all of these scopes.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(
+						stringvalidator.OneOf("openid", "profile", "chat_message.write"),
+					),
+				},
+			},
+			"bot_prompt": schema.StringAttribute{
+				Description: "Only apps with this add friends option.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("normal", "aggressive", "none"),
+				},
+			},
+			"apps": schema.ListNestedAttribute{
+				Description: "The LIFF apps matching all of the filters.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"liff_id": schema.StringAttribute{
+							Description: "The LIFF app ID",
+							Computed:    true,
+						},
+						"view": schema.SingleNestedAttribute{
+							Computed: true,
+							Attributes: map[string]schema.Attribute{
+								"type": schema.StringAttribute{
+									Description: "Screen size of the LIFF app. full, tall or compact are available",
+									Computed:    true,
+								},
+								"url": schema.StringAttribute{
+									Description: "The endpoint URL of the LIFF app",
+									Computed:    true,
+								},
+								"module_mode": schema.BoolAttribute{
+									Description: "if the LIFF app is in module mode",
+									Computed:    true,
+								},
+							},
+						},
+						"description": schema.StringAttribute{
+							Description: "Name of LIFF app",
+							Computed:    true,
+						},
+						"permanent_link_pattern": schema.StringAttribute{
+							Description: "How to add LIFF URL. value concat will be return.",
+							Computed:    true,
+						},
+						"features": schema.SingleNestedAttribute{
+							Computed: true,
+							Attributes: map[string]schema.Attribute{
+								"ble": schema.BoolAttribute{
+									Description: "If Bluetooth Low Energy (BLE) is available with LINE Things service.",
+									Computed:    true,
+								},
+								"qr_code": schema.BoolAttribute{
+									Description: "If QR code reader is available within the LIFF app.",
+									Computed:    true,
+								},
+							},
+						},
+						"scope": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+						},
+						"bot_prompt": schema.StringAttribute{
+							Computed: true,
+						},
+						"view_url": schema.StringAttribute{
+							Description: "The endpoint URL of the LIFF app. Same as view.url.",
+							Computed:    true,
+						},
+						"liff_url": schema.StringAttribute{
+							Description: "The LIFF URL which opens the LIFF app, such as https://liff.line.me/{liff_id}.",
+							Computed:    true,
+						},
+						"legacy_url": schema.StringAttribute{
+							Description: "The deprecated line://app/{liff_id} form of the LIFF URL.",
+							Computed:    true,
+						},
+						"permanent_link_template": schema.StringAttribute{
+							Description: "How a page under the endpoint URL is linked when permanent_link_pattern is concat. {path} is appended to the path and {query} is merged into the query of view.url.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *appsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state appsDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var descriptionRegex, viewURLRegex *regexp.Regexp
+	var err error
+
+	if !state.DescriptionRegex.IsNull() {
+		descriptionRegex, err = regexp.Compile(state.DescriptionRegex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("description_regex"), "Invalid regular expression", err.Error())
+		}
+	}
+
+	if !state.ViewURLRegex.IsNull() {
+		viewURLRegex, err = regexp.Compile(state.ViewURLRegex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("view_url_regex"), "Invalid regular expression", err.Error())
+		}
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	liffApps, err := d.client.ListLiffApps(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list LIFF apps", err.Error())
+		return
+	}
+
+	state.Apps = []appDataSourceModel{}
+
+	for _, liffApp := range liffApps {
+		if !state.ViewType.IsNull() && liffApp.View.Type != state.ViewType.ValueString() {
+			continue
+		}
+
+		if descriptionRegex != nil && (liffApp.Description == nil || !descriptionRegex.MatchString(*liffApp.Description)) {
+			continue
+		}
+
+		if viewURLRegex != nil && !viewURLRegex.MatchString(liffApp.View.URL) {
+			continue
+		}
+
+		if !state.BotPrompt.IsNull() && liffApp.BotPrompt != state.BotPrompt.ValueString() {
+			continue
+		}
+
+		hasScopes := true
+		for _, scope := range state.Scope {
+			if !slices.Contains(liffApp.Scope, scope.ValueString()) {
+				hasScopes = false
+				break
+			}
+		}
+		if !hasScopes {
+			continue
+		}
+
+		state.Apps = append(state.Apps, newAppDataSourceModel(liffApp))
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
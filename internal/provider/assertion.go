@@ -0,0 +1,221 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// assertionAudience is the "aud" claim required by LINE regardless of the endpoint.
+	assertionAudience = "https://api.line.me/"
+	// assertionLifetime is the lifetime of the JWT itself. LINE accepts 30 minutes at most.
+	assertionLifetime = 30 * time.Minute
+
+	defaultAssertionTokenExpiresIn = 15 * time.Minute
+	maxAssertionTokenExpiresIn     = 30 * 24 * time.Hour
+)
+
+// jwtAssertion signs JWTs to issue channel access tokens v2.1 with a registered assertion signing key.
+type jwtAssertion struct {
+	ChannelId string
+	Kid       string
+	Key       *rsa.PrivateKey
+	// TokenExpiresIn is the lifetime of issued channel access tokens (the "token_exp" claim).
+	TokenExpiresIn time.Duration
+}
+
+type ChannelAccessTokenV21Response struct {
+	TokenType   string `json:"token_type"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	KeyId       string `json:"key_id"`
+}
+
+// useJWTAssertion makes the client issue channel access tokens v2.1 with the JWT assertion
+// instead of stateless channel access tokens v3.
+func (c *LineApiClient) useJWTAssertion(assertion *jwtAssertion) {
+	c.issueToken = func(ctx context.Context) (string, time.Time, error) {
+		return c.issueChannelAccessTokenV21(ctx, assertion)
+	}
+}
+
+func (c *LineApiClient) issueChannelAccessTokenV21(ctx context.Context, assertion *jwtAssertion) (string, time.Time, error) {
+	jwt, err := assertion.sign(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	data := url.Values{
+		"grant_type":            []string{"client_credentials"},
+		"client_assertion_type": []string{"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      []string{jwt},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.Endpoint+"oauth2/v2.1/token", bytes.NewBufferString(data.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// Channel access tokens v2.1 are long-lived and limited to 30 per channel, so the request
+	// is not retried after a timeout or 5xx which may have issued a token anyway.
+	resp, err := c.doRequest(ctx, req, false)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", time.Time{}, newLineAPIError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var tokenResponse ChannelAccessTokenV21Response
+	err = json.Unmarshal(body, &tokenResponse)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if tokenResponse.TokenType != "Bearer" {
+		return "", time.Time{}, fmt.Errorf("unexpected token type: %s", tokenResponse.TokenType)
+	}
+
+	expiresAt := time.Now().Add(time.Second * time.Duration(tokenResponse.ExpiresIn))
+
+	return tokenResponse.AccessToken, expiresAt, nil
+}
+
+// sign returns a JWT signed with RS256 as described in
+// https://developers.line.biz/en/docs/messaging-api/generate-json-web-token/
+func (a *jwtAssertion) sign(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": a.Kid,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"iss":       a.ChannelId,
+		"sub":       a.ChannelId,
+		"aud":       assertionAudience,
+		"exp":       now.Add(assertionLifetime).Unix(),
+		"token_exp": int64(a.TokenExpiresIn.Seconds()),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.Key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey parses an RSA private key given as a JWK (JSON) or PEM (PKCS #1 or PKCS #8).
+func parseRSAPrivateKey(data string) (*rsa.PrivateKey, error) {
+	data = strings.TrimSpace(data)
+
+	if strings.HasPrefix(data, "{") {
+		return parseRSAPrivateKeyJWK([]byte(data))
+	}
+
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("private key must be a JWK or a PEM encoded RSA private key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key must be an RSA key, got: %T", parsed)
+	}
+	return key, nil
+}
+
+type rsaPrivateJWK struct {
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	D   string `json:"d"`
+	P   string `json:"p"`
+	Q   string `json:"q"`
+}
+
+func parseRSAPrivateKeyJWK(data []byte) (*rsa.PrivateKey, error) {
+	var jwk rsaPrivateJWK
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, fmt.Errorf("failed to parse JWK: %w", err)
+	}
+
+	if jwk.Kty != "RSA" {
+		return nil, fmt.Errorf("JWK must be an RSA key, got kty: %q", jwk.Kty)
+	}
+
+	values := map[string]*big.Int{}
+	for name, encoded := range map[string]string{"n": jwk.N, "e": jwk.E, "d": jwk.D, "p": jwk.P, "q": jwk.Q} {
+		if encoded == "" {
+			return nil, fmt.Errorf("JWK is missing %q, a private key is required", name)
+		}
+		decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q in JWK: %w", name, err)
+		}
+		values[name] = new(big.Int).SetBytes(decoded)
+	}
+
+	if !values["e"].IsInt64() {
+		return nil, fmt.Errorf("invalid \"e\" in JWK")
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{
+			N: values["n"],
+			E: int(values["e"].Int64()),
+		},
+		D:      values["d"],
+		Primes: []*big.Int{values["p"], values["q"]},
+	}
+
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid JWK: %w", err)
+	}
+	key.Precompute()
+
+	return key, nil
+}
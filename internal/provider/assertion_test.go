@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRSAPrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	jwk := fmt.Sprintf(`{"kty":"RSA","alg":"RS256","use":"sig","n":%q,"e":%q,"d":%q,"p":%q,"q":%q}`,
+		encode(key.N), encode(big.NewInt(int64(key.E))), encode(key.D), encode(key.Primes[0]), encode(key.Primes[1]))
+
+	cases := map[string]string{
+		"PKCS1": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"PKCS8": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})),
+		"JWK":   jwk,
+	}
+
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := parseRSAPrivateKey(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parsed.Equal(key) {
+				t.Error("parsed key differs from the original key")
+			}
+		})
+	}
+
+	if _, err := parseRSAPrivateKey(`{"kty":"RSA","n":"AQAB","e":"AQAB"}`); err == nil {
+		t.Error("expected an error for a public JWK")
+	}
+}
+
+func TestIssueChannelAccessTokenV21(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth2/v2.1/token" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.FormValue("client_assertion_type") != "urn:ietf:params:oauth:client-assertion-type:jwt-bearer" {
+			t.Errorf("unexpected client_assertion_type: %s", r.FormValue("client_assertion_type"))
+		}
+
+		parts := strings.Split(r.FormValue("client_assertion"), ".")
+		if len(parts) != 3 {
+			t.Errorf("malformed JWT: %s", r.FormValue("client_assertion"))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], signature); err != nil {
+			t.Errorf("invalid signature: %s", err)
+		}
+
+		var header map[string]any
+		headerJSON, _ := base64.RawURLEncoding.DecodeString(parts[0])
+		_ = json.Unmarshal(headerJSON, &header)
+		if header["kid"] != "test-kid" || header["alg"] != "RS256" {
+			t.Errorf("unexpected header: %v", header)
+		}
+
+		var claims map[string]any
+		claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		_ = json.Unmarshal(claimsJSON, &claims)
+		if claims["iss"] != "1234567890" || claims["sub"] != "1234567890" || claims["aud"] != "https://api.line.me/" {
+			t.Errorf("unexpected claims: %v", claims)
+		}
+		if claims["token_exp"] != float64(900) {
+			t.Errorf("unexpected token_exp: %v", claims["token_exp"])
+		}
+
+		fmt.Fprint(w, `{"token_type":"Bearer","access_token":"v21-token","expires_in":900,"key_id":"key"}`)
+	}))
+
+	client.useJWTAssertion(&jwtAssertion{
+		ChannelId:      "1234567890",
+		Kid:            "test-kid",
+		Key:            key,
+		TokenExpiresIn: 15 * time.Minute,
+	})
+
+	token, err := client.accessToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "v21-token" {
+		t.Errorf("expected v21-token, got %s", token)
+	}
+}
+
+func TestIssueChannelAccessTokenV21_NotRetried(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempts := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	client.MaxRetries = 3
+	client.RetryMinWait = time.Millisecond
+	client.RetryMaxWait = time.Millisecond
+	client.useJWTAssertion(&jwtAssertion{ChannelId: "1234567890", Kid: "test-kid", Key: key, TokenExpiresIn: 15 * time.Minute})
+
+	if _, err := client.accessToken(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected the token request not to be retried, got %d attempts", attempts)
+	}
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,12 +12,25 @@ import (
 )
 
 type LineApiClient struct {
-	HttpClient     *http.Client
-	ChannelId      string
-	ChannelSecret  string
-	Endpoint       string
-	AccessToken    string
-	TokenExpiresAt time.Time
+	HttpClient    *http.Client
+	ChannelId     string
+	ChannelSecret string
+	Endpoint      string
+	MaxRetries    int
+	RetryMinWait  time.Duration
+	RetryMaxWait  time.Duration
+	// AppListCacheTTL is how long the LIFF app list is cached. 0 disables the cache.
+	AppListCacheTTL time.Duration
+	// DefaultDeletionProtection is deletion_protection of liff_app when it is not configured.
+	DefaultDeletionProtection bool
+	// MaxAppsPerChannel is the number of LIFF apps checked when creation is planned. 0 disables the check.
+	MaxAppsPerChannel int
+
+	// issueToken issues a channel access token. Defaults to the stateless channel access token v3.
+	issueToken tokenFetchFunc
+	token      tokenCache
+	apps       appListCache
+	quota      appQuota
 }
 
 type StatelessChannelAccessTokenV3Response struct {
@@ -27,20 +41,27 @@ type StatelessChannelAccessTokenV3Response struct {
 
 func LineMessagingAPIClient(channel_id string, channel_secret string) (*LineApiClient, error) {
 	c := LineApiClient{
-		HttpClient:    &http.Client{Timeout: 10 * time.Second},
-		Endpoint:      "https://api.line.me/",
-		ChannelId:     channel_id,
-		ChannelSecret: channel_secret,
-	}
+		HttpClient:        &http.Client{Timeout: defaultRequestTimeout},
+		Endpoint:          defaultEndpoint,
+		ChannelId:         channel_id,
+		ChannelSecret:     channel_secret,
+		MaxRetries:        defaultMaxRetries,
+		RetryMinWait:      defaultRetryMinWait,
+		RetryMaxWait:      defaultRetryMaxWait,
+		AppListCacheTTL:   defaultAppListCacheTTL,
+		MaxAppsPerChannel: defaultMaxAppsPerChannel,
+	}
+	c.issueToken = c.issueStatelessChannelAccessTokenV3
 	return &c, nil
 }
 
-func (c *LineApiClient) GetStatelessChannelAccessTokenV3() (string, error) {
-
-	if time.Now().Before(c.TokenExpiresAt) {
-		return c.AccessToken, nil
-	}
+// accessToken returns a cached channel access token issued by issueToken, issuing a new one
+// when the cached token is about to expire. It is safe for concurrent use.
+func (c *LineApiClient) accessToken(ctx context.Context) (string, error) {
+	return c.token.get(ctx, c.issueToken)
+}
 
+func (c *LineApiClient) issueStatelessChannelAccessTokenV3(ctx context.Context) (string, time.Time, error) {
 	oauth_url := c.Endpoint + "oauth2/v3/token"
 
 	data := url.Values{
@@ -49,48 +70,51 @@ func (c *LineApiClient) GetStatelessChannelAccessTokenV3() (string, error) {
 		"client_secret": []string{c.ChannelSecret},
 	}
 
-	req, err := http.NewRequest("POST", oauth_url, bytes.NewBufferString(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", oauth_url, bytes.NewBufferString(data.Encode()))
 
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	resp, err := c.HttpClient.Do(req)
+	resp, err := c.doRequest(ctx, req, true)
 
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
+	defer resp.Body.Close()
+
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", time.Time{}, newLineAPIError(resp)
 	}
 
-	defer resp.Body.Close()
-
 	// レスポンスの内容を読み取る
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	var tokenResponse StatelessChannelAccessTokenV3Response
 
 	err = json.Unmarshal(body, &tokenResponse)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	if tokenResponse.TokenType != "Bearer" {
-		return "", fmt.Errorf("unexpected token type: %s", tokenResponse.TokenType)
+		return "", time.Time{}, fmt.Errorf("unexpected token type: %s", tokenResponse.TokenType)
 	}
 
-	c.AccessToken = tokenResponse.AccessToken
-	c.TokenExpiresAt = time.Now().Add(time.Second * time.Duration(tokenResponse.ExpiresIn))
+	expiresAt := time.Now().Add(time.Second * time.Duration(tokenResponse.ExpiresIn))
 
-	return c.AccessToken, nil
+	return tokenResponse.AccessToken, expiresAt, nil
 }
 
+// The LIFF API types below are written by hand and follow liff.yml of LINE's OpenAPI
+// specification (https://github.com/line/line-openapi). They are to be replaced with types
+// generated by go generate once the specification is vendored into this repository.
+
 type LiffAppsListResponseItemView struct {
 	Type       string `json:"type"`
 	URL        string `json:"url"`
@@ -116,29 +140,46 @@ type LiffAppsListResponse struct {
 	Apps []LiffAppsListResponseItem `json:"apps"`
 }
 
-func (c *LineApiClient) ListLiffApps() ([]LiffAppsListResponseItem, error) {
-	accessToken, err := c.GetStatelessChannelAccessTokenV3()
+// ListLiffApps returns the LIFF apps of the channel. The list is cached for
+// AppListCacheTTL and shared by every resource and data source of the provider.
+func (c *LineApiClient) ListLiffApps(ctx context.Context) ([]LiffAppsListResponseItem, error) {
+	return c.apps.get(ctx, c.AppListCacheTTL, c.fetchLiffApps)
+}
+
+func (c *LineApiClient) fetchLiffApps(ctx context.Context) ([]LiffAppsListResponseItem, error) {
+	accessToken, err := c.accessToken(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	url := c.Endpoint + "liff/v1/apps"
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
-	resp, err := c.HttpClient.Do(req)
+	resp, err := c.doRequest(ctx, req, true)
 
 	if err != nil {
 		return nil, err
 	}
 
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		err := newLineAPIError(resp)
+		// LINE responds 404 instead of an empty list when the channel has no LIFF apps.
+		if isNoLiffAppError(err) {
+			return []LiffAppsListResponseItem{}, nil
+		}
+		// Other 404s, such as from a proxy or a wrong endpoint, must not look like every LIFF app was deleted.
+		return nil, fmt.Errorf("%w: %w", ErrUnexpectedNotFound, err)
+	}
+
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newLineAPIError(resp)
 	}
 
-	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -152,8 +193,10 @@ func (c *LineApiClient) ListLiffApps() ([]LiffAppsListResponseItem, error) {
 	return liffAppsListResponse.Apps, nil
 }
 
-func (c *LineApiClient) GetLiffApp(liffId string) (LiffAppsListResponseItem, error) {
-	liffApps, err := c.ListLiffApps()
+// GetLiffApp returns the LIFF app with the given ID. ErrLiffAppNotFound is
+// returned when the channel has no such app.
+func (c *LineApiClient) GetLiffApp(ctx context.Context, liffId string) (LiffAppsListResponseItem, error) {
+	liffApps, err := c.ListLiffApps(ctx)
 	if err != nil {
 		return LiffAppsListResponseItem{}, err
 	}
@@ -170,7 +213,7 @@ func (c *LineApiClient) GetLiffApp(liffId string) (LiffAppsListResponseItem, err
 	}
 
 	if !found {
-		return LiffAppsListResponseItem{}, fmt.Errorf("LIFF app with id: %s not found", liffId)
+		return LiffAppsListResponseItem{}, fmt.Errorf("LIFF app with id: %s %w", liffId, ErrLiffAppNotFound)
 	}
 	return target, nil
 }
@@ -198,9 +241,9 @@ type LiffAppCreateResponse struct {
 	LiffId string `json:"liffId"`
 }
 
-func (c *LineApiClient) CreateLiffApp(request LiffAppCreateRequest) (string, error) {
+func (c *LineApiClient) CreateLiffApp(ctx context.Context, request LiffAppCreateRequest) (string, error) {
 
-	accessToken, err := c.GetStatelessChannelAccessTokenV3()
+	accessToken, err := c.accessToken(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -211,23 +254,24 @@ func (c *LineApiClient) CreateLiffApp(request LiffAppCreateRequest) (string, err
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.HttpClient.Do(req)
+	resp, err := c.doRequest(ctx, req, false)
 	if err != nil {
 		return "", err
 	}
 
+	defer resp.Body.Close()
+
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", newLineAPIError(resp)
 	}
 
-	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
@@ -237,6 +281,9 @@ func (c *LineApiClient) CreateLiffApp(request LiffAppCreateRequest) (string, err
 	if err != nil {
 		return "", err
 	}
+
+	c.apps.invalidate()
+
 	return createLiffAppResponse.LiffId, nil
 }
 
@@ -259,9 +306,9 @@ type LiffAppUpdateRequest struct {
 	BotPrompt            *string                       `json:"botPrompt,omitempty"`
 }
 
-func (c *LineApiClient) UpdateLiffApp(liffId string, request LiffAppUpdateRequest) error {
+func (c *LineApiClient) UpdateLiffApp(ctx context.Context, liffId string, request LiffAppUpdateRequest) error {
 
-	accessToken, err := c.GetStatelessChannelAccessTokenV3()
+	accessToken, err := c.accessToken(ctx)
 	if err != nil {
 		return err
 	}
@@ -272,47 +319,59 @@ func (c *LineApiClient) UpdateLiffApp(liffId string, request LiffAppUpdateReques
 		return err
 	}
 
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.HttpClient.Do(req)
+	resp, err := c.doRequest(ctx, req, true)
 	if err != nil {
 		return err
 	}
 
+	defer resp.Body.Close()
+
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return newLineAPIError(resp)
 	}
 
+	c.apps.invalidate()
+
 	return nil
 }
 
-func (c *LineApiClient) DeleteLiffApp(liffId string) error {
+func (c *LineApiClient) DeleteLiffApp(ctx context.Context, liffId string) error {
 
-	accessToken, err := c.GetStatelessChannelAccessTokenV3()
+	accessToken, err := c.accessToken(ctx)
 	if err != nil {
 		return err
 	}
 
 	url := c.Endpoint + "liff/v1/apps/" + liffId
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 
-	resp, err := c.HttpClient.Do(req)
+	resp, err := c.doRequest(ctx, req, true)
 	if err != nil {
 		return err
 	}
 
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		c.apps.remove(liffId)
+	}
+
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return newLineAPIError(resp)
 	}
 
+	c.apps.remove(liffId)
+
 	return nil
 }
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrLiffAppNotFound is returned when the LIFF app does not exist in the channel.
+var ErrLiffAppNotFound = errors.New("not found")
+
+// ErrUnexpectedNotFound is returned with the LineAPIError when the LIFF app list responds 404
+// without coming from LINE. It does not mean that LIFF apps do not exist.
+var ErrUnexpectedNotFound = errors.New("unexpected 404 from the LIFF app list")
+
+// LineAPIErrorDetail is an entry of the "details" array in LINE API error responses.
+type LineAPIErrorDetail struct {
+	Message  string `json:"message"`
+	Property string `json:"property"`
+}
+
+// LineAPIError is returned by LineApiClient when LINE API responds with a non-200 status.
+type LineAPIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	RequestId  string
+	Message    string
+	Details    []LineAPIErrorDetail
+	Body       string
+}
+
+type lineAPIErrorResponse struct {
+	Message string               `json:"message"`
+	Details []LineAPIErrorDetail `json:"details"`
+
+	// OAuth endpoints respond with RFC 6749 style errors.
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (e *LineAPIError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "LINE API %s %s failed with status %d", e.Method, e.Path, e.StatusCode)
+
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	} else if e.Body != "" {
+		fmt.Fprintf(&b, ": %s", e.Body)
+	}
+
+	for _, detail := range e.Details {
+		if detail.Property != "" {
+			fmt.Fprintf(&b, "\n  - %s: %s", detail.Property, detail.Message)
+		} else {
+			fmt.Fprintf(&b, "\n  - %s", detail.Message)
+		}
+	}
+
+	if e.RequestId != "" {
+		fmt.Fprintf(&b, "\n(X-Line-Request-Id: %s)", e.RequestId)
+	}
+
+	return b.String()
+}
+
+// newLineAPIError builds a LineAPIError from a non-200 response. The response body is consumed.
+func newLineAPIError(resp *http.Response) error {
+	apiErr := &LineAPIError{
+		StatusCode: resp.StatusCode,
+		RequestId:  resp.Header.Get("X-Line-Request-Id"),
+	}
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Path = resp.Request.URL.Path
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return apiErr
+	}
+	apiErr.Body = strings.TrimSpace(string(body))
+
+	var errorResponse lineAPIErrorResponse
+	if json.Unmarshal(body, &errorResponse) == nil {
+		apiErr.Message = errorResponse.Message
+		apiErr.Details = errorResponse.Details
+		if apiErr.Message == "" && errorResponse.Error != "" {
+			apiErr.Message = errorResponse.Error
+			if errorResponse.ErrorDescription != "" {
+				apiErr.Message += ": " + errorResponse.ErrorDescription
+			}
+		}
+	}
+
+	return apiErr
+}
+
+// isNotFound reports whether err means the LIFF app no longer exists.
+func isNotFound(err error) bool {
+	if errors.Is(err, ErrUnexpectedNotFound) {
+		return false
+	}
+	var apiErr *LineAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+	return errors.Is(err, ErrLiffAppNotFound)
+}
+
+// isNoLiffAppError reports whether err is the 404 which LINE responds to the LIFF app list
+// of a channel without LIFF apps. The message is not documented, so any 404 from LINE, which
+// always sets X-Line-Request-Id, is accepted.
+func isNoLiffAppError(err error) bool {
+	var apiErr *LineAPIError
+	return errors.As(err, &apiErr) &&
+		apiErr.StatusCode == http.StatusNotFound &&
+		apiErr.RequestId != ""
+}
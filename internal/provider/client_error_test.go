@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newErrorResponse(statusCode int, body string) *http.Response {
+	header := http.Header{}
+	header.Set("X-Line-Request-Id", "request-id")
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    &http.Request{Method: http.MethodPost, URL: &url.URL{Path: "/liff/v1/apps"}},
+	}
+}
+
+func TestNewLineAPIError(t *testing.T) {
+	cases := []struct {
+		name            string
+		body            string
+		expectedMessage string
+		expectedDetails []LineAPIErrorDetail
+		expectedError   string
+	}{
+		{
+			name:            "message and details",
+			body:            `{"message":"The request body has 1 error(s)","details":[{"message":"must be specified","property":"view.url"}]}`,
+			expectedMessage: "The request body has 1 error(s)",
+			expectedDetails: []LineAPIErrorDetail{{Message: "must be specified", Property: "view.url"}},
+			expectedError:   "LINE API POST /liff/v1/apps failed with status 400: The request body has 1 error(s)\n  - view.url: must be specified\n(X-Line-Request-Id: request-id)",
+		},
+		{
+			name:            "OAuth error",
+			body:            `{"error":"invalid_client","error_description":"invalid client_secret"}`,
+			expectedMessage: "invalid_client: invalid client_secret",
+			expectedError:   "LINE API POST /liff/v1/apps failed with status 400: invalid_client: invalid client_secret\n(X-Line-Request-Id: request-id)",
+		},
+		{
+			name:          "not JSON",
+			body:          "<html>Bad Request</html>\n",
+			expectedError: "LINE API POST /liff/v1/apps failed with status 400: <html>Bad Request</html>\n(X-Line-Request-Id: request-id)",
+		},
+		{
+			name:          "empty",
+			body:          "",
+			expectedError: "LINE API POST /liff/v1/apps failed with status 400\n(X-Line-Request-Id: request-id)",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := newLineAPIError(newErrorResponse(http.StatusBadRequest, c.body))
+
+			var apiErr *LineAPIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected LineAPIError, got %T", err)
+			}
+			if apiErr.StatusCode != http.StatusBadRequest || apiErr.Method != http.MethodPost || apiErr.Path != "/liff/v1/apps" || apiErr.RequestId != "request-id" {
+				t.Errorf("unexpected error: %+v", apiErr)
+			}
+			if apiErr.Message != c.expectedMessage {
+				t.Errorf("expected message %q, got %q", c.expectedMessage, apiErr.Message)
+			}
+			if fmt.Sprint(apiErr.Details) != fmt.Sprint(c.expectedDetails) {
+				t.Errorf("expected details %v, got %v", c.expectedDetails, apiErr.Details)
+			}
+			if err.Error() != c.expectedError {
+				t.Errorf("expected %q, got %q", c.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "404", err: newLineAPIError(newErrorResponse(http.StatusNotFound, "")), expected: true},
+		{name: "wrapped 404", err: fmt.Errorf("failed to delete: %w", newLineAPIError(newErrorResponse(http.StatusNotFound, ""))), expected: true},
+		{name: "unexpected 404 of the list", err: fmt.Errorf("%w: %w", ErrUnexpectedNotFound, newLineAPIError(newErrorResponse(http.StatusNotFound, ""))), expected: false},
+		{name: "400", err: newLineAPIError(newErrorResponse(http.StatusBadRequest, "")), expected: false},
+		{name: "missing in the list", err: fmt.Errorf("LIFF app with id: 1234567890-abcdefgh %w", ErrLiffAppNotFound), expected: true},
+		{name: "other error", err: errors.New("connection refused"), expected: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isNotFound(c.err); got != c.expected {
+				t.Errorf("expected %t, got %t", c.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryMinWait = 1 * time.Second
+	defaultRetryMaxWait = 30 * time.Second
+)
+
+// doRequest sends the request. When retryable is true, the request is retried on
+// network errors, 429 and 5xx responses with jittered exponential backoff.
+func (c *LineApiClient) doRequest(ctx context.Context, req *http.Request, retryable bool) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := c.HttpClient.Do(req)
+
+		// キャンセルやタイムアウトの場合はリトライしない
+		if ctx.Err() != nil {
+			return resp, err
+		}
+
+		if !retryable || attempt >= c.MaxRetries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		wait := c.retryWait(resp, attempt)
+
+		fields := map[string]any{
+			"method":  req.Method,
+			"path":    req.URL.Path,
+			"attempt": attempt + 1,
+			"wait":    wait.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status_code"] = resp.StatusCode
+			fields["request_id"] = resp.Header.Get("X-Line-Request-Id")
+			// 接続を再利用できるようにボディを読み捨てる
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		tflog.Warn(ctx, "Retrying LINE API request", fields)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryWait returns how long to wait before the next attempt. Retry-After is
+// honored when present, and the result never exceeds RetryMaxWait.
+func (c *LineApiClient) retryWait(resp *http.Response, attempt int) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, c.RetryMaxWait)
+		}
+	}
+
+	wait := c.RetryMinWait << attempt
+	if wait <= 0 || wait > c.RetryMaxWait {
+		wait = c.RetryMaxWait
+	}
+
+	// jitter between wait/2 and wait
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "empty", value: "", ok: false},
+		{name: "seconds", value: "3", expected: 3 * time.Second, ok: true},
+		{name: "zero", value: "0", expected: 0, ok: true},
+		{name: "negative", value: "-1", ok: false},
+		{name: "invalid", value: "soon", ok: false},
+		{name: "past date", value: "Mon, 02 Jan 2006 15:04:05 GMT", expected: 0, ok: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			wait, ok := parseRetryAfter(c.value)
+			if ok != c.ok || wait != c.expected {
+				t.Errorf("expected %s, %t, got %s, %t", c.expected, c.ok, wait, ok)
+			}
+		})
+	}
+
+	t.Run("future date", func(t *testing.T) {
+		wait, ok := parseRetryAfter(time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat))
+		if !ok || wait <= 8*time.Second || wait > 10*time.Second {
+			t.Errorf("expected about 10s, got %s, %t", wait, ok)
+		}
+	})
+}
+
+func TestRetryWait(t *testing.T) {
+	client := &LineApiClient{RetryMinWait: time.Second, RetryMaxWait: 5 * time.Second}
+
+	retryAfter := func(value string) *http.Response {
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {value}}}
+	}
+
+	cases := []struct {
+		name    string
+		resp    *http.Response
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "Retry-After", resp: retryAfter("2"), attempt: 0, min: 2 * time.Second, max: 2 * time.Second},
+		{name: "Retry-After capped", resp: retryAfter("120"), attempt: 0, min: 5 * time.Second, max: 5 * time.Second},
+		{name: "invalid Retry-After", resp: retryAfter("-1"), attempt: 0, min: 500 * time.Millisecond, max: time.Second},
+		{name: "network error", resp: nil, attempt: 1, min: time.Second, max: 2 * time.Second},
+		{name: "backoff", resp: &http.Response{StatusCode: http.StatusInternalServerError}, attempt: 2, min: 2 * time.Second, max: 4 * time.Second},
+		{name: "backoff capped", resp: &http.Response{StatusCode: http.StatusInternalServerError}, attempt: 10, min: 2500 * time.Millisecond, max: 5 * time.Second},
+		{name: "backoff overflow", resp: nil, attempt: 80, min: 2500 * time.Millisecond, max: 5 * time.Second},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				if wait := client.retryWait(c.resp, c.attempt); wait < c.min || wait > c.max {
+					t.Fatalf("expected between %s and %s, got %s", c.min, c.max, wait)
+				}
+			}
+		})
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	cases := []struct {
+		name     string
+		resp     *http.Response
+		err      error
+		expected bool
+	}{
+		{name: "network error", err: errors.New("connection reset"), expected: true},
+		{name: "200", resp: &http.Response{StatusCode: http.StatusOK}, expected: false},
+		{name: "400", resp: &http.Response{StatusCode: http.StatusBadRequest}, expected: false},
+		{name: "401", resp: &http.Response{StatusCode: http.StatusUnauthorized}, expected: false},
+		{name: "404", resp: &http.Response{StatusCode: http.StatusNotFound}, expected: false},
+		{name: "429", resp: &http.Response{StatusCode: http.StatusTooManyRequests}, expected: true},
+		{name: "500", resp: &http.Response{StatusCode: http.StatusInternalServerError}, expected: true},
+		{name: "503", resp: &http.Response{StatusCode: http.StatusServiceUnavailable}, expected: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := shouldRetry(c.resp, c.err); got != c.expected {
+				t.Errorf("expected %t, got %t", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestDoRequest_ReplaysBodyOnRetry(t *testing.T) {
+	var mu sync.Mutex
+	bodies := []string{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	client.MaxRetries = 3
+	client.RetryMinWait = time.Millisecond
+	client.RetryMaxWait = time.Millisecond
+
+	ctx := context.Background()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, client.Endpoint+"liff/v1/apps/1234567890-abcdefgh", bytes.NewBufferString(`{"description":"app"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.doRequest(ctx, req, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 after retries, got %d", resp.StatusCode)
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if body != `{"description":"app"}` {
+			t.Errorf("attempt %d: expected the body to be sent again, got %q", i+1, body)
+		}
+	}
+}
+
+func TestDoRequest_NotRetriedWhenNotRetryable(t *testing.T) {
+	attempts := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	client.MaxRetries = 3
+	client.RetryMinWait = time.Millisecond
+
+	ctx := context.Background()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, client.Endpoint+"liff/v1/apps", nil)
+	resp, err := client.doRequest(ctx, req, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestDoRequest_CancelledDuringBackoff(t *testing.T) {
+	attempted := make(chan struct{}, 10)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempted <- struct{}{}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	client.MaxRetries = 3
+	client.RetryMinWait = time.Minute
+	client.RetryMaxWait = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-attempted
+		cancel()
+	}()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, client.Endpoint+"liff/v1/apps", nil)
+	start := time.Now()
+	_, err := client.doRequest(ctx, req, true)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the backoff to be interrupted, took %s", elapsed)
+	}
+	if len(attempted) != 0 {
+		t.Errorf("expected no attempt after cancellation, got %d more", len(attempted))
+	}
+}
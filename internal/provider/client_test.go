@@ -0,0 +1,316 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *LineApiClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := LineMessagingAPIClient("1234567890", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Endpoint = server.URL + "/"
+	client.MaxRetries = 0
+
+	return client
+}
+
+func tokenHandler(issued *atomic.Int32, expiresIn int, delay time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := issued.Add(1)
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token_type":"Bearer","access_token":"token-%d","expires_in":%d}`, n, expiresIn)
+	}
+}
+
+func TestAccessToken_ConcurrentCallersShareOneRequest(t *testing.T) {
+	var issued atomic.Int32
+	client := newTestClient(t, tokenHandler(&issued, 900, 50*time.Millisecond))
+
+	const callers = 20
+	tokens := make([]string, callers)
+	errs := make([]error, callers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], errs[i] = client.accessToken(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: unexpected error: %s", i, errs[i])
+		}
+		if tokens[i] != "token-1" {
+			t.Errorf("caller %d: expected token-1, got %s", i, tokens[i])
+		}
+	}
+
+	if got := issued.Load(); got != 1 {
+		t.Errorf("expected 1 token request, got %d", got)
+	}
+
+	// cached token is reused
+	if _, err := client.accessToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := issued.Load(); got != 1 {
+		t.Errorf("expected cached token to be reused, got %d token requests", got)
+	}
+}
+
+func TestAccessToken_RefreshesAheadOfExpiry(t *testing.T) {
+	var issued atomic.Int32
+	// expires within tokenRefreshMargin
+	client := newTestClient(t, tokenHandler(&issued, 30, 0))
+
+	first, err := client.accessToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.accessToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Errorf("expected a new token, got %s twice", first)
+	}
+	if got := issued.Load(); got != 2 {
+		t.Errorf("expected 2 token requests, got %d", got)
+	}
+}
+
+func TestAccessToken_ErrorIsNotCached(t *testing.T) {
+	var issued atomic.Int32
+	succeed := tokenHandler(&issued, 900, 0)
+	var calls atomic.Int32
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("X-Line-Request-Id", "req-1")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_client","error_description":"invalid client_secret"}`)
+			return
+		}
+		succeed(w, r)
+	}))
+
+	_, err := client.accessToken(context.Background())
+
+	var apiErr *LineAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected LineAPIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.RequestId != "req-1" {
+		t.Errorf("unexpected error: %#v", apiErr)
+	}
+
+	token, err := client.accessToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-1" {
+		t.Errorf("expected token-1, got %s", token)
+	}
+}
+
+func TestAccessToken_CancelledCallerDoesNotFailOthers(t *testing.T) {
+	var issued atomic.Int32
+	client := newTestClient(t, tokenHandler(&issued, 900, 100*time.Millisecond))
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := client.accessToken(cancelled)
+		leaderErr <- err
+	}()
+
+	// wait until the first request is in flight
+	for issued.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	followerErr := make(chan error, 1)
+	go func() {
+		_, err := client.accessToken(context.Background())
+		followerErr <- err
+	}()
+
+	cancel()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if err := <-followerErr; err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func liffAppsHandler(listed *atomic.Int32, apps *[]string, mu *sync.Mutex) http.Handler {
+	mux := http.NewServeMux()
+	var issued atomic.Int32
+	mux.Handle("/oauth2/v3/token", tokenHandler(&issued, 900, 0))
+	mux.HandleFunc("/liff/v1/apps", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			listed.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			fmt.Fprint(w, `{"apps":[`)
+			for i, liffId := range *apps {
+				if i > 0 {
+					fmt.Fprint(w, ",")
+				}
+				fmt.Fprintf(w, `{"liffId":%q,"view":{"type":"full","url":"https://example.com"}}`, liffId)
+			}
+			fmt.Fprint(w, `]}`)
+		case http.MethodPost:
+			liffId := fmt.Sprintf("1234567890-%08d", len(*apps))
+			*apps = append(*apps, liffId)
+			fmt.Fprintf(w, `{"liffId":%q}`, liffId)
+		}
+	})
+	mux.HandleFunc("/liff/v1/apps/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		liffId := r.URL.Path[len("/liff/v1/apps/"):]
+		remaining := (*apps)[:0]
+		for _, id := range *apps {
+			if id != liffId {
+				remaining = append(remaining, id)
+			}
+		}
+		*apps = remaining
+	})
+	return mux
+}
+
+func TestListLiffApps_CachesAcrossConcurrentCallers(t *testing.T) {
+	var listed atomic.Int32
+	var mu sync.Mutex
+	apps := []string{"1234567890-aaaaaaaa", "1234567890-bbbbbbbb"}
+	client := newTestClient(t, liffAppsHandler(&listed, &apps, &mu))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 25; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetLiffApp(context.Background(), "1234567890-bbbbbbbb"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := listed.Load(); got != 1 {
+		t.Errorf("expected 1 list request, got %d", got)
+	}
+}
+
+func TestListLiffApps_CacheFollowsChanges(t *testing.T) {
+	var listed atomic.Int32
+	var mu sync.Mutex
+	apps := []string{"1234567890-aaaaaaaa"}
+	client := newTestClient(t, liffAppsHandler(&listed, &apps, &mu))
+	ctx := context.Background()
+
+	if _, err := client.ListLiffApps(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	createdLiffId, err := client.CreateLiffApp(ctx, LiffAppCreateRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetLiffApp(ctx, createdLiffId); err != nil {
+		t.Fatalf("created app is not listed: %s", err)
+	}
+	if got := listed.Load(); got != 2 {
+		t.Errorf("expected the list to be fetched again after create, got %d list requests", got)
+	}
+
+	if err := client.DeleteLiffApp(ctx, createdLiffId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetLiffApp(ctx, createdLiffId); err == nil {
+		t.Error("deleted app is still listed")
+	}
+	if got := listed.Load(); got != 2 {
+		t.Errorf("expected the cached list to be patched after delete, got %d list requests", got)
+	}
+}
+
+func TestListLiffApps_NotFound(t *testing.T) {
+	cases := []struct {
+		name        string
+		requestId   string
+		contentType string
+		body        string
+		expectEmpty bool
+	}{
+		{name: "no LIFF app", requestId: "request-id", contentType: "application/json", body: `{"message":"There is no LIFF app on the channel"}`, expectEmpty: true},
+		{name: "other wording", requestId: "request-id", contentType: "application/json", body: `{"message":"Not found"}`, expectEmpty: true},
+		{name: "empty body", requestId: "request-id", expectEmpty: true},
+		{name: "not from LINE", contentType: "application/json", body: `{"message":"Not found"}`},
+		{name: "not JSON", contentType: "text/html", body: `<html>404 page not found</html>`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			var issued atomic.Int32
+			mux.Handle("/oauth2/v3/token", tokenHandler(&issued, 900, 0))
+			mux.HandleFunc("/liff/v1/apps", func(w http.ResponseWriter, r *http.Request) {
+				if c.requestId != "" {
+					w.Header().Set("X-Line-Request-Id", c.requestId)
+				}
+				if c.contentType != "" {
+					w.Header().Set("Content-Type", c.contentType)
+				}
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, c.body)
+			})
+			client := newTestClient(t, mux)
+
+			apps, err := client.ListLiffApps(context.Background())
+			if c.expectEmpty {
+				if err != nil || len(apps) != 0 {
+					t.Errorf("expected no LIFF apps, got %v, %v", apps, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrUnexpectedNotFound) {
+				t.Fatalf("expected ErrUnexpectedNotFound, got %v", err)
+			}
+			var apiErr *LineAPIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+				t.Errorf("expected the LineAPIError to be kept, got %v", err)
+			}
+			// Read must not remove every liff_app from the state.
+			if isNotFound(err) {
+				t.Errorf("expected the error not to mean the LIFF app was deleted: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ function.Function = &isValidLiffIdFunction{}
+)
+
+// NewIsValidLiffIdFunction is a helper function to simplify the provider implementation.
+func NewIsValidLiffIdFunction() function.Function {
+	return &isValidLiffIdFunction{}
+}
+
+// isValidLiffIdFunction checks the format of a LIFF ID.
+type isValidLiffIdFunction struct{}
+
+func (f *isValidLiffIdFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "is_valid_liff_id"
+}
+
+func (f *isValidLiffIdFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Check the format of a LIFF ID",
+		Description: "Returns true when the value is formatted as a LIFF ID, which is the channel ID and 8 alphanumeric characters joined with a hyphen. It does not check whether the LIFF app exists.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "id",
+				Description: "The value to check.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *isValidLiffIdFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var id string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &id))
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, isValidLiffId(id)))
+}
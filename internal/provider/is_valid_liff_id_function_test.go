@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestIsValidLiffIdFunction(t *testing.T) {
+	cases := map[string]bool{
+		"1234567890-AbCdEfGh":  true,
+		"1657000000-12345678":  true,
+		"1234567890":           false,
+		"1234567890-AbCdEfG":   false,
+		"1234567890-AbCdEfGhI": false,
+		"abc-AbCdEfGh":         false,
+		"1234567890-AbCd_fGh":  false,
+		"":                     false,
+	}
+
+	for id, expected := range cases {
+		result, err := runFunction(t, NewIsValidLiffIdFunction(), types.StringValue(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Equal(types.BoolValue(expected)) {
+			t.Errorf("%q: expected %t, got %s", id, expected, result)
+		}
+	}
+}
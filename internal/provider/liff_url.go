@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	liffURLPrefix       = "https://liff.line.me/"
+	legacyLiffURLPrefix = "line://app/"
+)
+
+// liffIdPattern matches LIFF IDs, which are "{channel ID}-{8 alphanumeric characters}".
+var liffIdPattern = regexp.MustCompile(`^[0-9]+-[A-Za-z0-9]{8}$`)
+
+func isValidLiffId(liffId string) bool {
+	return liffIdPattern.MatchString(liffId)
+}
+
+// liffURL returns the LIFF URL which opens the LIFF app.
+func liffURL(liffId string) string {
+	return liffURLPrefix + liffId
+}
+
+// legacyLiffURL returns the deprecated line:// scheme URL of the LIFF app.
+func legacyLiffURL(liffId string) string {
+	return legacyLiffURLPrefix + liffId
+}
+
+// permanentLinkTemplate shows how a path and query on the endpoint URL map to a permanent link.
+// Only the concat pattern is defined by LINE, so null is returned for the others.
+func permanentLinkTemplate(liffId string, permanentLinkPattern string) types.String {
+	if permanentLinkPattern != "concat" {
+		return types.StringNull()
+	}
+	return types.StringValue(liffURL(liffId) + "/{path}?{query}")
+}
+
+// buildPermanentLink returns the permanent link of the page at path and query under the
+// endpoint URL, following the concat pattern. The LIFF ID must be valid. Characters which can
+// not appear as they are, such as spaces, "?" and "#" in path, are percent-encoded, while
+// existing percent-encodings are kept.
+func buildPermanentLink(liffId string, path string, query string) (string, error) {
+	link := liffURL(liffId)
+
+	if path = strings.TrimPrefix(path, "/"); path != "" {
+		if _, err := url.PathUnescape(path); err != nil {
+			return "", fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		link += "/" + escapeURLPart(path, "?#")
+	}
+
+	if query = strings.TrimPrefix(query, "?"); query != "" {
+		if _, err := url.QueryUnescape(query); err != nil {
+			return "", fmt.Errorf("invalid query %q: %w", query, err)
+		}
+		link += "?" + escapeURLPart(query, "#")
+	}
+
+	if _, err := url.Parse(link); err != nil {
+		return "", err
+	}
+
+	return link, nil
+}
+
+// escapeURLPart percent-encodes the reserved characters, spaces, control characters and
+// non-ASCII bytes in s.
+func escapeURLPart(s string, reserved string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(reserved, c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// parsePermanentLink splits a LIFF URL, in either https://liff.line.me/ or line://app/ form,
+// into the LIFF ID, the additional path and the query. The fragment is ignored.
+func parsePermanentLink(link string) (liffId string, path string, query string, err error) {
+	var rest string
+	switch {
+	case strings.HasPrefix(link, liffURLPrefix):
+		rest = strings.TrimPrefix(link, liffURLPrefix)
+	case strings.HasPrefix(link, legacyLiffURLPrefix):
+		rest = strings.TrimPrefix(link, legacyLiffURLPrefix)
+	default:
+		return "", "", "", fmt.Errorf("not a LIFF URL: %q", link)
+	}
+
+	u, err := url.Parse("/" + rest)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	liffId, path, _ = strings.Cut(strings.TrimPrefix(u.EscapedPath(), "/"), "/")
+	if !isValidLiffId(liffId) {
+		return "", "", "", fmt.Errorf("invalid LIFF ID in %q", link)
+	}
+	if path != "" {
+		path = "/" + path
+	}
+
+	return liffId, path, u.RawQuery, nil
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ function.Function = &parseURLFunction{}
+)
+
+var parseURLReturnAttrTypes = map[string]attr.Type{
+	"liff_id": types.StringType,
+	"path":    types.StringType,
+	"query":   types.StringType,
+}
+
+// NewParseURLFunction is a helper function to simplify the provider implementation.
+func NewParseURLFunction() function.Function {
+	return &parseURLFunction{}
+}
+
+// parseURLFunction splits a LIFF URL into the LIFF ID, path and query.
+type parseURLFunction struct{}
+
+func (f *parseURLFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_url"
+}
+
+func (f *parseURLFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Parse a LIFF URL",
+		Description: "Splits a LIFF URL, either `https://liff.line.me/{liff_id}/...` or `line://app/{liff_id}/...`, into an object with liff_id, path and query. The fragment is ignored.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "url",
+				Description: "The LIFF URL to parse.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: parseURLReturnAttrTypes,
+		},
+	}
+}
+
+func (f *parseURLFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var link string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &link))
+	if resp.Error != nil {
+		return
+	}
+
+	liffId, path, query, err := parsePermanentLink(link)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	result, diags := types.ObjectValue(parseURLReturnAttrTypes, map[string]attr.Value{
+		"liff_id": types.StringValue(liffId),
+		"path":    types.StringValue(path),
+		"query":   types.StringValue(query),
+	})
+	resp.Error = function.FuncErrorFromDiags(ctx, diags)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, result))
+}
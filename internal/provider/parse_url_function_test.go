@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestParseURLFunction(t *testing.T) {
+	cases := []struct {
+		url    string
+		liffId string
+		path   string
+		query  string
+	}{
+		{"https://liff.line.me/1234567890-AbCdEfGh", "1234567890-AbCdEfGh", "", ""},
+		{"https://liff.line.me/1234567890-AbCdEfGh/items/1?tab=detail#top", "1234567890-AbCdEfGh", "/items/1", "tab=detail"},
+		{"line://app/1234567890-AbCdEfGh?ref=qr", "1234567890-AbCdEfGh", "", "ref=qr"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			result, err := runFunction(t, NewParseURLFunction(), types.StringValue(c.url))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := types.ObjectValueMust(parseURLReturnAttrTypes, map[string]attr.Value{
+				"liff_id": types.StringValue(c.liffId),
+				"path":    types.StringValue(c.path),
+				"query":   types.StringValue(c.query),
+			})
+			if !result.Equal(expected) {
+				t.Errorf("expected %s, got %s", expected, result)
+			}
+
+			// round trip
+			link, err := runFunction(t, NewURLFunction(),
+				types.StringValue(c.liffId), types.StringValue(c.path), types.StringValue(c.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := runFunction(t, NewParseURLFunction(), link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !again.Equal(expected) {
+				t.Errorf("round trip of %s returned %s", link, again)
+			}
+		})
+	}
+}
+
+func TestParseURLFunction_Invalid(t *testing.T) {
+	for _, url := range []string{
+		"https://example.com/1234567890-AbCdEfGh",
+		"https://liff.line.me/",
+		"https://liff.line.me/invalid/items",
+	} {
+		if _, err := runFunction(t, NewParseURLFunction(), types.StringValue(url)); err == nil {
+			t.Errorf("expected an error for %s", url)
+		}
+	}
+}
@@ -2,19 +2,31 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"os"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/providervalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider = &liffProvider{}
+	_ provider.Provider                     = &liffProvider{}
+	_ provider.ProviderWithConfigValidators = &liffProvider{}
+	_ provider.ProviderWithFunctions        = &liffProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -32,11 +44,37 @@ type liffProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// wrapTransport wraps the HTTP transport of the LINE API client, such as
+	// to record and replay LINE API interactions in tests.
+	wrapTransport func(http.RoundTripper) http.RoundTripper
 }
 
 type liffProviderModel struct {
-	ChannelId     types.String `tfsdk:"channel_id"`
-	ChannelSecret types.String `tfsdk:"channel_secret"`
+	ChannelId       types.String `tfsdk:"channel_id"`
+	ChannelSecret   types.String `tfsdk:"channel_secret"`
+	MaxRetries      types.Int64  `tfsdk:"max_retries"`
+	RetryMaxWait    types.String `tfsdk:"retry_max_wait"`
+	AppListCacheTTL types.String `tfsdk:"app_list_cache_ttl"`
+	Endpoint        types.String `tfsdk:"endpoint"`
+	RequestTimeout  types.String `tfsdk:"request_timeout"`
+	ProxyURL        types.String `tfsdk:"proxy_url"`
+	CACertFile      types.String `tfsdk:"ca_cert_file"`
+	ClientCertFile  types.String `tfsdk:"client_cert_file"`
+	ClientKeyFile   types.String `tfsdk:"client_key_file"`
+
+	DeletionProtection types.Bool  `tfsdk:"deletion_protection"`
+	MaxAppsPerChannel  types.Int64 `tfsdk:"max_apps_per_channel"`
+
+	AccessToken  types.String                `tfsdk:"access_token"`
+	TokenCommand []types.String              `tfsdk:"token_command"`
+	Assertion    *liffProviderAssertionModel `tfsdk:"assertion"`
+}
+
+type liffProviderAssertionModel struct {
+	Kid            types.String `tfsdk:"kid"`
+	PrivateKey     types.String `tfsdk:"private_key"`
+	TokenExpiresIn types.String `tfsdk:"token_expires_in"`
 }
 
 // Metadata returns the provider type name.
@@ -50,18 +88,111 @@ func (p *liffProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"channel_id": schema.StringAttribute{
-				Description: "The LINE Channel ID. This can also be set via the LINE_CHANNEL_ID environment variable.",
+				Description: "The LINE Channel ID. Not required when `access_token` or `token_command` is set. This can also be set via the LINE_CHANNEL_ID environment variable.",
 				Optional:    true,
 			},
 			"channel_secret": schema.StringAttribute{
-				Description: "The LINE Channel Secret. This can also be set via the LINE_CHANNEL_SECRET environment variable.",
+				Description: "The LINE Channel Secret. Not required when `assertion`, `access_token` or `token_command` is set. This can also be set via the LINE_CHANNEL_SECRET environment variable.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: "Maximum number of retries for idempotent LINE API requests which fail with 429, 5xx or a network error. Defaults to 3. Set 0 to disable retries.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"max_apps_per_channel": schema.Int64Attribute{
+				Description: "Maximum number of LIFF apps in the channel. Planning to create `liff_app` beyond it fails, and a warning is shown close to it. Defaults to 30, the limit of LINE. Set 0 to disable the check.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"retry_max_wait": schema.StringAttribute{
+				Description: "Maximum time to wait between retries as a duration string such as `30s`. Defaults to `30s`.",
+				Optional:    true,
+			},
+			"app_list_cache_ttl": schema.StringAttribute{
+				Description: "How long the LIFF app list of the channel is cached and shared across resources and data sources, as a duration string such as `1m`. The cache is updated after every create, update and delete. Defaults to `1m`. Set `0s` to disable the cache.",
+				Optional:    true,
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Description: "The default of `deletion_protection` of `liff_app` resources which do not set it. Defaults to false.",
+				Optional:    true,
+			},
+			"endpoint": schema.StringAttribute{
+				Description: "The base URL of LINE API. Defaults to `https://api.line.me/`. This can also be set via the LINE_API_ENDPOINT environment variable.",
+				Optional:    true,
+			},
+			"request_timeout": schema.StringAttribute{
+				Description: "Timeout of a single HTTP request to LINE API as a duration string such as `10s`. Defaults to `10s`. `0s` disables it, so that only the `timeouts` of each resource apply. This can also be set via the LINE_API_REQUEST_TIMEOUT environment variable.",
+				Optional:    true,
+			},
+			"proxy_url": schema.StringAttribute{
+				Description: "URL of the HTTP(S) proxy to connect to LINE API through. Defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables. This can also be set via the LINE_API_PROXY_URL environment variable.",
+				Optional:    true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM encoded CA bundle trusted in addition to the system roots. This can also be set via the LINE_API_CA_CERT_FILE environment variable.",
+				Optional:    true,
+			},
+			"client_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM encoded client certificate for mutual TLS. Requires `client_key_file`. This can also be set via the LINE_API_CLIENT_CERT_FILE environment variable.",
+				Optional:    true,
+			},
+			"client_key_file": schema.StringAttribute{
+				Description: "Path to a PEM encoded private key of `client_cert_file`. This can also be set via the LINE_API_CLIENT_KEY_FILE environment variable.",
+				Optional:    true,
+			},
+			"access_token": schema.StringAttribute{
+				Description: "A pre-issued channel access token used instead of issuing one with channel_id and channel_secret. This can also be set via the LINE_CHANNEL_ACCESS_TOKEN environment variable, which is used only when none of `channel_secret`, `token_command` and `assertion` is set, and then takes precedence over LINE_CHANNEL_SECRET.",
 				Optional:    true,
 				Sensitive:   true,
 			},
+			"token_command": schema.ListAttribute{
+				Description: "A command and its arguments which print a channel access token as JSON such as `{\"access_token\": \"...\", \"expires_in\": 900}`. `expires_at` in RFC 3339 is also accepted. The command is run again when the token expires.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"assertion": schema.SingleNestedAttribute{
+				Description: "Issue channel access tokens v2.1 with a JWT signed by an assertion signing key instead of channel_secret.",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"kid": schema.StringAttribute{
+						Description: "The key ID returned when the public key was registered to the channel.",
+						Required:    true,
+					},
+					"private_key": schema.StringAttribute{
+						Description: "The RSA private key of the assertion signing key, as a JWK or PEM.",
+						Required:    true,
+						Sensitive:   true,
+					},
+					"token_expires_in": schema.StringAttribute{
+						Description: "Lifetime of issued channel access tokens as a duration string such as `15m`, up to 30 days. Defaults to `15m`. Every provider run issues a new token, and LINE allows at most 30 valid channel access tokens v2.1 per channel, so frequent runs such as in CI fail until earlier tokens expire. Keep it short for such use.",
+						Optional:    true,
+					},
+				},
+			},
 		},
 	}
 }
 
+// ConfigValidators ensures only one way of authentication is configured.
+func (p *liffProvider) ConfigValidators(_ context.Context) []provider.ConfigValidator {
+	return []provider.ConfigValidator{
+		providervalidator.Conflicting(
+			path.MatchRoot("access_token"),
+			path.MatchRoot("token_command"),
+			path.MatchRoot("assertion"),
+		),
+	}
+}
+
 // Configure prepares a HashiCups API client for data sources and resources.
 func (p *liffProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	tflog.Info(ctx, "Configuring LINE Messaging API client")
@@ -89,14 +220,33 @@ func (p *liffProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		channel_secret = config.ChannelSecret.ValueString()
 	}
 
-	if channel_id == "" {
+	// Credentials in the configuration take precedence over the environment variables, and
+	// LINE_CHANNEL_ACCESS_TOKEN takes precedence over LINE_CHANNEL_SECRET.
+	access_token := ""
+	if !config.AccessToken.IsNull() {
+		access_token = config.AccessToken.ValueString()
+	} else if config.TokenCommand == nil && config.Assertion == nil && config.ChannelSecret.IsNull() {
+		access_token = os.Getenv("LINE_CHANNEL_ACCESS_TOKEN")
+		if access_token != "" && channel_secret != "" {
+			resp.Diagnostics.AddWarning(
+				"LINE_CHANNEL_SECRET is ignored",
+				"Both LINE_CHANNEL_ACCESS_TOKEN and LINE_CHANNEL_SECRET are set, so the channel access token in LINE_CHANNEL_ACCESS_TOKEN is used and no token is issued with LINE_CHANNEL_SECRET. Unset either of them to silence this warning.",
+			)
+		}
+	}
+
+	// channel_id and channel_secret are not used with a pre-issued token
+	preIssuedToken := access_token != "" || config.TokenCommand != nil
+
+	if channel_id == "" && !preIssuedToken {
 		resp.Diagnostics.AddError(
 			"channel_id is required",
 			"channel_id is required",
 		)
 	}
 
-	if channel_secret == "" {
+	// channel_secret is not used with the JWT assertion
+	if channel_secret == "" && config.Assertion == nil && !preIssuedToken {
 		resp.Diagnostics.AddError(
 			"channel_secret is required",
 			"channel_secret is required",
@@ -120,16 +270,154 @@ func (p *liffProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	}
 
+	switch {
+	case access_token != "":
+		client.useAccessToken(access_token)
+	case config.TokenCommand != nil:
+		command := make([]string, 0, len(config.TokenCommand))
+		for _, arg := range config.TokenCommand {
+			command = append(command, arg.ValueString())
+		}
+		client.useTokenCommand(command)
+	case config.Assertion != nil:
+		assertion, ok := jwtAssertionFromConfig(&resp.Diagnostics, channel_id, config.Assertion)
+		if !ok {
+			return
+		}
+		client.useJWTAssertion(assertion)
+	}
+
+	endpoint := stringValueOrEnv(config.Endpoint, "LINE_API_ENDPOINT")
+	if endpoint != "" {
+		client.Endpoint, err = normalizeEndpoint(endpoint)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("endpoint"), "Invalid endpoint", err.Error())
+			return
+		}
+	}
+
+	httpClientConfig := httpClientConfig{
+		Timeout:        defaultRequestTimeout,
+		ProxyURL:       stringValueOrEnv(config.ProxyURL, "LINE_API_PROXY_URL"),
+		CACertFile:     stringValueOrEnv(config.CACertFile, "LINE_API_CA_CERT_FILE"),
+		ClientCertFile: stringValueOrEnv(config.ClientCertFile, "LINE_API_CLIENT_CERT_FILE"),
+		ClientKeyFile:  stringValueOrEnv(config.ClientKeyFile, "LINE_API_CLIENT_KEY_FILE"),
+	}
+
+	if requestTimeout := stringValueOrEnv(config.RequestTimeout, "LINE_API_REQUEST_TIMEOUT"); requestTimeout != "" {
+		timeout, ok := parseDurationAttribute(&resp.Diagnostics, "request_timeout", types.StringValue(requestTimeout), true)
+		if !ok {
+			return
+		}
+		httpClientConfig.Timeout = timeout
+	}
+
+	client.HttpClient, err = newHttpClient(httpClientConfig)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to configure HTTP client",
+			"Failed to configure HTTP client for LINE API: "+err.Error(),
+		)
+		return
+	}
+	if p.wrapTransport != nil {
+		client.HttpClient.Transport = p.wrapTransport(client.HttpClient.Transport)
+	}
+
+	if !config.MaxRetries.IsNull() {
+		client.MaxRetries = int(config.MaxRetries.ValueInt64())
+	}
+
+	if !config.RetryMaxWait.IsNull() {
+		retryMaxWait, ok := parseDurationAttribute(&resp.Diagnostics, "retry_max_wait", config.RetryMaxWait, false)
+		if !ok {
+			return
+		}
+		client.RetryMaxWait = retryMaxWait
+		client.RetryMinWait = min(client.RetryMinWait, retryMaxWait)
+	}
+
+	if !config.AppListCacheTTL.IsNull() {
+		appListCacheTTL, ok := parseDurationAttribute(&resp.Diagnostics, "app_list_cache_ttl", config.AppListCacheTTL, true)
+		if !ok {
+			return
+		}
+		client.AppListCacheTTL = appListCacheTTL
+	}
+
+	client.DefaultDeletionProtection = config.DeletionProtection.ValueBool()
+
+	if !config.MaxAppsPerChannel.IsNull() {
+		client.MaxAppsPerChannel = int(config.MaxAppsPerChannel.ValueInt64())
+	}
+
 	resp.DataSourceData = client
 	resp.ResourceData = client
 
 	tflog.Info(ctx, "Configured LINE Messaging API client", map[string]any{"success": true})
 }
 
+func jwtAssertionFromConfig(diags *diag.Diagnostics, channelId string, config *liffProviderAssertionModel) (*jwtAssertion, bool) {
+	key, err := parseRSAPrivateKey(config.PrivateKey.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("assertion").AtName("private_key"), "Invalid private_key", err.Error())
+		return nil, false
+	}
+
+	assertion := &jwtAssertion{
+		ChannelId:      channelId,
+		Kid:            config.Kid.ValueString(),
+		Key:            key,
+		TokenExpiresIn: defaultAssertionTokenExpiresIn,
+	}
+
+	if !config.TokenExpiresIn.IsNull() {
+		tokenExpiresIn, err := time.ParseDuration(config.TokenExpiresIn.ValueString())
+		if err != nil || tokenExpiresIn < time.Second || tokenExpiresIn > maxAssertionTokenExpiresIn {
+			diags.AddAttributeError(
+				path.Root("assertion").AtName("token_expires_in"),
+				"Invalid token_expires_in",
+				fmt.Sprintf("token_expires_in must be a duration between 1s and 720h, got: %q", config.TokenExpiresIn.ValueString()),
+			)
+			return nil, false
+		}
+		assertion.TokenExpiresIn = tokenExpiresIn
+	}
+
+	return assertion, true
+}
+
+// stringValueOrEnv returns the attribute value, or the environment variable when the attribute is not set.
+func stringValueOrEnv(value types.String, env string) string {
+	if value.IsNull() {
+		return os.Getenv(env)
+	}
+	return value.ValueString()
+}
+
+// parseDurationAttribute parses a duration string attribute such as "30s".
+func parseDurationAttribute(diags *diag.Diagnostics, name string, value types.String, allowZero bool) (time.Duration, bool) {
+	duration, err := time.ParseDuration(value.ValueString())
+	if err != nil || duration < 0 || (duration == 0 && !allowZero) {
+		expected := "a positive"
+		if allowZero {
+			expected = "a non-negative"
+		}
+		diags.AddAttributeError(
+			path.Root(name),
+			"Invalid "+name,
+			fmt.Sprintf("%s must be %s duration such as \"30s\", got: %q", name, expected, value.ValueString()),
+		)
+		return 0, false
+	}
+	return duration, true
+}
+
 // DataSources defines the data sources implemented in the provider.
 func (p *liffProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewAppDataSource,
+		NewAppsDataSource,
 	}
 }
 
@@ -139,3 +427,12 @@ func (p *liffProvider) Resources(_ context.Context) []func() resource.Resource {
 		NewAppResource,
 	}
 }
+
+// Functions defines the provider-defined functions implemented in the provider.
+func (p *liffProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewURLFunction,
+		NewParseURLFunction,
+		NewIsValidLiffIdFunction,
+	}
+}
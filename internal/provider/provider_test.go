@@ -0,0 +1,217 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"terraform-provider-liff/internal/cassette"
+	"terraform-provider-liff/internal/linefake"
+)
+
+// testAccProtoV6ProviderFactories runs the provider in the test process during acceptance testing.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"liff": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// newTestAccFake starts a fake LINE platform for acceptance testing, and returns it with
+// the provider configuration which connects to it.
+func newTestAccFake(t *testing.T, opts linefake.Options) (*linefake.Server, string) {
+	t.Helper()
+
+	fake := linefake.New(opts)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	providerConfig := fmt.Sprintf(`
+provider "liff" {
+  channel_id     = %q
+  channel_secret = %q
+  endpoint       = %q
+  retry_max_wait = "1s"
+}
+`, fake.ChannelId(), fake.ChannelSecret(), server.URL)
+
+	return fake, providerConfig
+}
+
+// newTestAccCassette returns provider factories and configuration which replay the cassette
+// testdata/cassettes/<test name>.json, so that the test runs without credentials.
+//
+// When LINE_API_CASSETTE_MODE is "record", the cassette is recorded instead against the LINE API
+// configured by LINE_CHANNEL_ID, LINE_CHANNEL_SECRET and optionally LINE_API_ENDPOINT.
+func newTestAccCassette(t *testing.T) (map[string]func() (tfprotov6.ProviderServer, error), string) {
+	t.Helper()
+
+	mode := cassette.ModeReplay
+	if os.Getenv("LINE_API_CASSETTE_MODE") == string(cassette.ModeRecord) {
+		mode = cassette.ModeRecord
+	}
+
+	recorder, err := cassette.New(filepath.Join("testdata", "cassettes", t.Name()+".json"), mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := recorder.Save(); err != nil {
+			t.Errorf("failed to save cassette: %s", err)
+		}
+	})
+
+	// Every Terraform command configures the provider again, and shares the recorder.
+	factories := map[string]func() (tfprotov6.ProviderServer, error){
+		"liff": providerserver.NewProtocol6WithError(&liffProvider{
+			version:       "test",
+			wrapTransport: recorder.Wrap,
+		}),
+	}
+
+	if mode == cassette.ModeRecord {
+		return factories, `
+provider "liff" {}
+`
+	}
+	return factories, `
+provider "liff" {
+  channel_id     = "1234567890"
+  channel_secret = "replayed"
+  retry_max_wait = "1s"
+}
+`
+}
+
+// runFunction calls the provider-defined function with the arguments and returns its result.
+func runFunction(t *testing.T, f function.Function, args ...attr.Value) (attr.Value, *function.FuncError) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	var definition function.DefinitionResponse
+	f.Definition(ctx, function.DefinitionRequest{}, &definition)
+
+	result, funcErr := definition.Definition.Return.NewResultData(ctx)
+	if funcErr != nil {
+		t.Fatal(funcErr)
+	}
+
+	resp := function.RunResponse{Result: result}
+	f.Run(ctx, function.RunRequest{Arguments: function.NewArgumentsData(args)}, &resp)
+
+	return resp.Result.Value(), resp.Error
+}
+
+func TestParseDurationAttribute(t *testing.T) {
+	cases := []struct {
+		value         string
+		allowZero     bool
+		expected      time.Duration
+		expectedError string
+	}{
+		{value: "30s", expected: 30 * time.Second},
+		{value: "0s", allowZero: true, expected: 0},
+		{value: "0s", expectedError: "must be a positive duration"},
+		{value: "-1s", allowZero: true, expectedError: "must be a non-negative duration"},
+		{value: "soon", expectedError: "must be a positive duration"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			var diags diag.Diagnostics
+			duration, ok := parseDurationAttribute(&diags, "app_list_cache_ttl", types.StringValue(c.value), c.allowZero)
+
+			if c.expectedError == "" {
+				if !ok || duration != c.expected || diags.HasError() {
+					t.Errorf("expected %s, got %s, %v", c.expected, duration, diags)
+				}
+				return
+			}
+			if ok || len(diags) != 1 || !strings.Contains(diags[0].Detail(), c.expectedError) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedError, diags)
+			}
+		})
+	}
+}
+
+func testAccCheckFakeTokenNotIssued(fake *linefake.Server) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		for _, request := range fake.Requests() {
+			if strings.HasPrefix(request.Path, "/oauth2/") {
+				return fmt.Errorf("expected no token to be issued, got %s %s", request.Method, request.Path)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccProvider_accessToken(t *testing.T) {
+	fake := linefake.New(linefake.Options{})
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	// access_token in the configuration takes precedence over the environment variable.
+	t.Setenv("LINE_CHANNEL_ACCESS_TOKEN", "invalid")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "liff" {
+  access_token = %q
+  endpoint     = %q
+}
+
+data "liff_apps" "test" {}
+`, fake.IssueToken(), server.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.liff_apps.test", "apps.#", "0"),
+					testAccCheckFakeTokenNotIssued(fake),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_accessTokenEnv(t *testing.T) {
+	fake := linefake.New(linefake.Options{})
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	// LINE_CHANNEL_ACCESS_TOKEN takes precedence over LINE_CHANNEL_SECRET.
+	t.Setenv("LINE_CHANNEL_ID", fake.ChannelId())
+	t.Setenv("LINE_CHANNEL_SECRET", "invalid")
+	t.Setenv("LINE_CHANNEL_ACCESS_TOKEN", fake.IssueToken())
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "liff" {
+  endpoint = %q
+}
+
+data "liff_apps" "test" {}
+`, server.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.liff_apps.test", "apps.#", "0"),
+					testAccCheckFakeTokenNotIssued(fake),
+				),
+			},
+		},
+	})
+}
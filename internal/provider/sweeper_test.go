@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"terraform-provider-liff/internal/linefake"
+)
+
+// defaultSweepPrefix is the description prefix of LIFF apps created by acceptance tests.
+const defaultSweepPrefix = "tf-acc-"
+
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	// LINE has no regions, so any value works such as: go test ./internal/provider -v -sweep=all
+	resource.AddTestSweepers("liff_app", &resource.Sweeper{
+		Name: "liff_app",
+		F: func(_ string) error {
+			client, err := sweeperClient()
+			if err != nil {
+				return err
+			}
+
+			prefix := os.Getenv("LIFF_SWEEP_PREFIX")
+			if prefix == "" {
+				prefix = defaultSweepPrefix
+			}
+			return sweepLiffApps(context.Background(), client, prefix)
+		},
+	})
+}
+
+// sweeperClient returns the client for the channel configured by LINE_CHANNEL_ID,
+// LINE_CHANNEL_SECRET and optionally LINE_API_ENDPOINT.
+func sweeperClient() (*LineApiClient, error) {
+	channelId := os.Getenv("LINE_CHANNEL_ID")
+	channelSecret := os.Getenv("LINE_CHANNEL_SECRET")
+	if channelId == "" || channelSecret == "" {
+		return nil, fmt.Errorf("LINE_CHANNEL_ID and LINE_CHANNEL_SECRET are required to sweep LIFF apps")
+	}
+
+	client, err := LineMessagingAPIClient(channelId, channelSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint := os.Getenv("LINE_API_ENDPOINT"); endpoint != "" {
+		client.Endpoint, err = normalizeEndpoint(endpoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// sweepLiffApps deletes the LIFF apps whose description starts with prefix.
+func sweepLiffApps(ctx context.Context, client *LineApiClient, prefix string) error {
+	if prefix == "" {
+		return fmt.Errorf("refusing to sweep every LIFF app with an empty prefix")
+	}
+
+	liffApps, err := client.ListLiffApps(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list LIFF apps: %w", err)
+	}
+
+	var errs []error
+	for _, liffApp := range liffApps {
+		if liffApp.Description == nil || !strings.HasPrefix(*liffApp.Description, prefix) {
+			continue
+		}
+
+		err := client.DeleteLiffApp(ctx, liffApp.LiffId)
+		if err != nil && !isNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete LIFF app %s: %w", liffApp.LiffId, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func TestSweepLiffApps(t *testing.T) {
+	fake := linefake.New(linefake.Options{})
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	for _, description := range []string{"tf-acc-leaked", "tf-acc-another", "production", "shop tf-acc-"} {
+		fake.AddApp(linefake.App{
+			View:        linefake.View{Type: "full", URL: "https://example.com"},
+			Description: description,
+			Scope:       []string{"openid"},
+		})
+	}
+
+	t.Setenv("LINE_CHANNEL_ID", fake.ChannelId())
+	t.Setenv("LINE_CHANNEL_SECRET", fake.ChannelSecret())
+	t.Setenv("LINE_API_ENDPOINT", server.URL)
+	client, err := sweeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sweepLiffApps(context.Background(), client, ""); err == nil {
+		t.Error("expected an empty prefix to be refused")
+	}
+
+	if err := sweepLiffApps(context.Background(), client, defaultSweepPrefix); err != nil {
+		t.Fatal(err)
+	}
+
+	descriptions := []string{}
+	for _, app := range fake.Apps() {
+		descriptions = append(descriptions, app.Description)
+	}
+	if strings.Join(descriptions, ",") != "production,shop tf-acc-" {
+		t.Errorf("expected only the test LIFF apps to be deleted, got %v", descriptions)
+	}
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before the expiry a cached token is refreshed,
+// so that a token never expires while a request is in flight.
+const tokenRefreshMargin = 1 * time.Minute
+
+type tokenFetchFunc func(ctx context.Context) (token string, expiresAt time.Time, err error)
+
+// tokenFetch is a token request in flight. done is closed when it completes.
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// tokenCache caches an access token. It is safe for concurrent use, and
+// concurrent callers share a single in-flight token request.
+type tokenCache struct {
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	inflight  *tokenFetch
+}
+
+func (t *tokenCache) get(ctx context.Context, fetch tokenFetchFunc) (string, error) {
+	for {
+		t.mu.Lock()
+		if t.token != "" && time.Now().Add(tokenRefreshMargin).Before(t.expiresAt) {
+			token := t.token
+			t.mu.Unlock()
+			return token, nil
+		}
+
+		call := t.inflight
+		if call == nil {
+			call = &tokenFetch{done: make(chan struct{})}
+			t.inflight = call
+			t.mu.Unlock()
+			return t.fetch(ctx, call, fetch)
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-call.done:
+		}
+
+		// The caller which issued the request has been cancelled. Try again
+		// with our own context unless we are cancelled too.
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			continue
+		}
+		return call.token, call.err
+	}
+}
+
+func (t *tokenCache) fetch(ctx context.Context, call *tokenFetch, fetch tokenFetchFunc) (string, error) {
+	token, expiresAt, err := fetch(ctx)
+
+	t.mu.Lock()
+	if err == nil {
+		t.token = token
+		t.expiresAt = expiresAt
+	}
+	t.inflight = nil
+	t.mu.Unlock()
+
+	call.token = token
+	call.err = err
+	close(call.done)
+
+	return token, err
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// noExpiry is used for tokens whose expiry is unknown. They are cached for the process lifetime.
+var noExpiry = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// tokenCommandOutput is the JSON printed by token_command.
+type tokenCommandOutput struct {
+	AccessToken string `json:"access_token"`
+	// ExpiresIn is the lifetime of the token in seconds.
+	ExpiresIn *int64 `json:"expires_in,omitempty"`
+	// ExpiresAt is the expiry of the token in RFC 3339.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// useAccessToken makes the client use the pre-issued channel access token as is.
+func (c *LineApiClient) useAccessToken(accessToken string) {
+	c.issueToken = func(_ context.Context) (string, time.Time, error) {
+		return accessToken, noExpiry, nil
+	}
+}
+
+// useTokenCommand makes the client obtain channel access tokens from the command.
+// The command is run again when the token is about to expire.
+func (c *LineApiClient) useTokenCommand(command []string) {
+	c.issueToken = func(ctx context.Context) (string, time.Time, error) {
+		return runTokenCommand(ctx, command)
+	}
+}
+
+func runTokenCommand(ctx context.Context, command []string) (string, time.Time, error) {
+	tflog.Debug(ctx, "Running token_command", map[string]any{"command": command[0]})
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", time.Time{}, fmt.Errorf("token_command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var output tokenCommandOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return "", time.Time{}, fmt.Errorf("token_command must print JSON such as {\"access_token\": \"...\", \"expires_in\": 900}: %w", err)
+	}
+
+	if output.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("token_command printed no access_token")
+	}
+
+	expiresAt := noExpiry
+	switch {
+	case output.ExpiresAt != nil:
+		expiresAt = *output.ExpiresAt
+	case output.ExpiresIn != nil:
+		expiresAt = time.Now().Add(time.Duration(*output.ExpiresIn) * time.Second)
+	}
+
+	return output.AccessToken, expiresAt, nil
+}
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTokenCommand_RunsAgainWhenExpired(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "counter")
+
+	// prints a token which expires immediately, numbered by the number of runs
+	script := `echo x >> "$0"; n=$(wc -l < "$0" | tr -d ' '); printf '{"access_token":"token-%s","expires_in":0}' "$n"`
+
+	client, err := LineMessagingAPIClient("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.useTokenCommand([]string{"sh", "-c", script, counter})
+
+	for _, expected := range []string{"token-1", "token-2"} {
+		token, err := client.accessToken(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != expected {
+			t.Errorf("expected %s, got %s", expected, token)
+		}
+	}
+}
+
+func TestTokenCommand_ReportsFailure(t *testing.T) {
+	client, err := LineMessagingAPIClient("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.useTokenCommand([]string{"sh", "-c", "echo broker unavailable >&2; exit 1"})
+
+	_, err = client.accessToken(context.Background())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "broker unavailable") {
+		t.Errorf("expected stderr in the error, got: %s", err)
+	}
+}
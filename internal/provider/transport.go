@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	defaultEndpoint       = "https://api.line.me/"
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// httpClientConfig is the transport configuration of the LINE API client.
+type httpClientConfig struct {
+	Timeout        time.Duration
+	ProxyURL       string
+	CACertFile     string
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// newHttpClient builds an http.Client from the config. The proxy falls back
+// to HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func newHttpClient(config httpClientConfig) (*http.Client, error) {
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", config.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if config.CACertFile != "" || config.ClientCertFile != "" || config.ClientKeyFile != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+		if config.CACertFile != "" {
+			pem, err := os.ReadFile(config.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+			}
+			rootCAs, err := x509.SystemCertPool()
+			if err != nil {
+				rootCAs = x509.NewCertPool()
+			}
+			if !rootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no PEM certificate found in %s", config.CACertFile)
+			}
+			tlsConfig.RootCAs = rootCAs
+		}
+
+		if config.ClientCertFile != "" || config.ClientKeyFile != "" {
+			if config.ClientCertFile == "" || config.ClientKeyFile == "" {
+				return nil, fmt.Errorf("both client certificate and client key are required")
+			}
+			certificate, err := tls.LoadX509KeyPair(config.ClientCertFile, config.ClientKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+	}, nil
+}
+
+// normalizeEndpoint validates the endpoint URL and ensures it ends with a slash.
+func normalizeEndpoint(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("endpoint must be an absolute http(s) URL, got: %q", endpoint)
+	}
+	if u.Path == "" || u.Path[len(u.Path)-1] != '/' {
+		u.Path += "/"
+	}
+	return u.String(), nil
+}
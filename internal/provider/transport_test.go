@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed certificate and its key, and returns their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "terraform-provider-liff test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewHttpClient(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	invalidCAFile := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalidCAFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name          string
+		config        httpClientConfig
+		expectedError string
+	}{
+		{name: "default", config: httpClientConfig{Timeout: time.Second}},
+		{name: "proxy", config: httpClientConfig{ProxyURL: "http://proxy.example.com:3128"}},
+		{name: "invalid proxy", config: httpClientConfig{ProxyURL: "http://proxy example.com"}, expectedError: "invalid proxy URL"},
+		{name: "CA bundle", config: httpClientConfig{CACertFile: certFile}},
+		{name: "missing CA bundle", config: httpClientConfig{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}, expectedError: "failed to read CA certificate"},
+		{name: "invalid CA bundle", config: httpClientConfig{CACertFile: invalidCAFile}, expectedError: "no PEM certificate found"},
+		{name: "client certificate", config: httpClientConfig{ClientCertFile: certFile, ClientKeyFile: keyFile}},
+		{name: "certificate without key", config: httpClientConfig{ClientCertFile: certFile}, expectedError: "both client certificate and client key are required"},
+		{name: "key without certificate", config: httpClientConfig{ClientKeyFile: keyFile}, expectedError: "both client certificate and client key are required"},
+		{name: "mismatched key", config: httpClientConfig{ClientCertFile: certFile, ClientKeyFile: certFile}, expectedError: "failed to load client certificate"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, err := newHttpClient(c.config)
+			if c.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+					t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if client.Timeout != c.config.Timeout {
+				t.Errorf("expected timeout %s, got %s", c.config.Timeout, client.Timeout)
+			}
+
+			transport := client.Transport.(*http.Transport)
+			if c.config.CACertFile != "" && transport.TLSClientConfig.RootCAs == nil {
+				t.Error("expected the CA bundle to be trusted")
+			}
+			if c.config.ClientCertFile != "" && len(transport.TLSClientConfig.Certificates) != 1 {
+				t.Error("expected the client certificate to be loaded")
+			}
+		})
+	}
+
+	t.Run("proxy is used", func(t *testing.T) {
+		client, err := newHttpClient(httpClientConfig{ProxyURL: "http://proxy.example.com:3128"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "https://api.line.me/liff/v1/apps", nil)
+		proxyURL, err := client.Transport.(*http.Transport).Proxy(req)
+		if err != nil || proxyURL == nil || proxyURL.String() != "http://proxy.example.com:3128" {
+			t.Errorf("expected the configured proxy, got %v, %v", proxyURL, err)
+		}
+	})
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	cases := []struct {
+		endpoint string
+		expected string
+		invalid  bool
+	}{
+		{endpoint: "https://api.line.me", expected: "https://api.line.me/"},
+		{endpoint: "https://api.line.me/", expected: "https://api.line.me/"},
+		{endpoint: "http://localhost:8080/line", expected: "http://localhost:8080/line/"},
+		{endpoint: "http://localhost:8080/line/", expected: "http://localhost:8080/line/"},
+		{endpoint: "api.line.me", invalid: true},
+		{endpoint: "ftp://api.line.me/", invalid: true},
+		{endpoint: "https://", invalid: true},
+		{endpoint: "://api.line.me", invalid: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.endpoint, func(t *testing.T) {
+			got, err := normalizeEndpoint(c.endpoint)
+			if c.invalid {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil || got != c.expected {
+				t.Errorf("expected %q, got %q, %v", c.expected, got, err)
+			}
+		})
+	}
+}
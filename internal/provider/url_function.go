@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ function.Function = &urlFunction{}
+)
+
+// NewURLFunction is a helper function to simplify the provider implementation.
+func NewURLFunction() function.Function {
+	return &urlFunction{}
+}
+
+// urlFunction builds the permanent link of a page of a LIFF app.
+type urlFunction struct{}
+
+func (f *urlFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "url"
+}
+
+func (f *urlFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Build a LIFF permanent link",
+		Description: "Returns the permanent link which opens the page at path and query under the endpoint URL of the LIFF app, following the concat permanent link pattern. Pass empty strings to omit path or query.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "liff_id",
+				Description: "The LIFF ID.",
+			},
+			function.StringParameter{
+				Name:        "path",
+				Description: "The path appended to the endpoint URL, such as `/items/1`. Spaces, `?`, `#` and non-ASCII characters are percent-encoded.",
+			},
+			function.StringParameter{
+				Name:        "query",
+				Description: "The query string merged into the endpoint URL, such as `tab=detail`. Spaces, `#` and non-ASCII characters are percent-encoded.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *urlFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var liffId, path, query string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &liffId, &path, &query))
+	if resp.Error != nil {
+		return
+	}
+
+	if !isValidLiffId(liffId) {
+		resp.Error = function.NewArgumentFuncError(0, "Invalid LIFF ID: "+liffId)
+		return
+	}
+
+	link, err := buildPermanentLink(liffId, path, query)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, link))
+}
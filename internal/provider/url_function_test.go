@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestURLFunction(t *testing.T) {
+	cases := []struct {
+		name     string
+		liffId   string
+		path     string
+		query    string
+		expected string
+	}{
+		{"liff id only", "1234567890-AbCdEfGh", "", "", "https://liff.line.me/1234567890-AbCdEfGh"},
+		{"path", "1234567890-AbCdEfGh", "/items/1", "", "https://liff.line.me/1234567890-AbCdEfGh/items/1"},
+		{"path without slash", "1234567890-AbCdEfGh", "items/1", "", "https://liff.line.me/1234567890-AbCdEfGh/items/1"},
+		{"query", "1234567890-AbCdEfGh", "", "tab=detail", "https://liff.line.me/1234567890-AbCdEfGh?tab=detail"},
+		{"path and query", "1234567890-AbCdEfGh", "/items/1", "?tab=detail&ref=qr", "https://liff.line.me/1234567890-AbCdEfGh/items/1?tab=detail&ref=qr"},
+		{"path with space", "1234567890-AbCdEfGh", "/items/new item", "", "https://liff.line.me/1234567890-AbCdEfGh/items/new%20item"},
+		{"path with ? and #", "1234567890-AbCdEfGh", "/faq?#1", "", "https://liff.line.me/1234567890-AbCdEfGh/faq%3F%231"},
+		{"escaped path", "1234567890-AbCdEfGh", "/items/new%20item", "", "https://liff.line.me/1234567890-AbCdEfGh/items/new%20item"},
+		{"non-ASCII path", "1234567890-AbCdEfGh", "/商品", "", "https://liff.line.me/1234567890-AbCdEfGh/%E5%95%86%E5%93%81"},
+		{"query with space and #", "1234567890-AbCdEfGh", "", "q=a b#c", "https://liff.line.me/1234567890-AbCdEfGh?q=a%20b%23c"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := runFunction(t, NewURLFunction(),
+				types.StringValue(c.liffId), types.StringValue(c.path), types.StringValue(c.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Equal(types.StringValue(c.expected)) {
+				t.Errorf("expected %s, got %s", c.expected, result)
+			}
+		})
+	}
+}
+
+func TestURLFunction_InvalidLiffId(t *testing.T) {
+	_, err := runFunction(t, NewURLFunction(),
+		types.StringValue("not-a-liff-id"), types.StringValue(""), types.StringValue(""))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if err.FunctionArgument == nil || *err.FunctionArgument != 0 {
+		t.Errorf("expected an error on the first argument, got: %s", err)
+	}
+}
+
+func TestURLFunction_InvalidEscape(t *testing.T) {
+	cases := map[string][2]string{
+		"path":  {"/100%", ""},
+		"query": {"", "discount=100%"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := runFunction(t, NewURLFunction(),
+				types.StringValue("1234567890-AbCdEfGh"), types.StringValue(c[0]), types.StringValue(c[1]))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}